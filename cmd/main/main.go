@@ -26,6 +26,10 @@ func init() {
 	r.AddCommand(cmd.Daemon)
 	r.AddCommand(cmd.Login)
 	r.AddCommand(cmd.Logout)
+	r.AddCommand(cmd.Account)
+	r.AddCommand(cmd.Audit)
+	r.AddCommand(cmd.Ban)
+	r.AddCommand(cmd.Export)
 }
 
 // Main Function
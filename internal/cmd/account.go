@@ -0,0 +1,262 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.mau.fi/whatsmeow/store"
+
+	pkgDatastore "github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/datastore"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/log"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/pii"
+	pkgWhatsApp "github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/whatsapp"
+)
+
+var (
+	accountTag,
+	accountPersona,
+	accountPIIRedaction,
+	accountPIIRestore string
+	accountAllow,
+	accountPIIDetectors []string
+)
+
+// Account Variable Structure
+var Account = &cobra.Command{
+	Use:   "account",
+	Short: "Manage logged-in WhatsApp accounts",
+	Long:  "Manage Logged-in WhatsApp Accounts of Go WhatsApp Multi-Device GPT",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// AccountList Variable Structure
+var AccountList = &cobra.Command{
+	Use:   "list",
+	Short: "List logged-in WhatsApp accounts",
+	Long:  "List Logged-in WhatsApp Accounts of Go WhatsApp Multi-Device GPT",
+	Run: func(cmd *cobra.Command, args []string) {
+		devices, err := pkgWhatsApp.WhatsAppDatastore.GetAllDevices(context.Background())
+		if err != nil {
+			log.WithError(err).Println(log.LogLevelError, "Failed to Load WhatsApp Client Devices from Datastore")
+			return
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "ACCOUNT\tTAG\tPERSONA\tALLOW\tPII")
+
+		for _, device := range devices {
+			account, err := pkgDatastore.GetAccount(context.Background(), device.ID.ToNonAD().String())
+			if err != nil {
+				log.WithError(err).Println(log.LogLevelError, "Failed to Load WhatsApp Account Settings from Datastore")
+				return
+			}
+
+			tag := account.Tag
+			if len(tag) == 0 {
+				tag = pkgWhatsApp.WhatsAppGPTTag() + " (default)"
+			}
+
+			persona := account.Persona
+			if len(persona) == 0 {
+				persona = "(default)"
+			} else if len(persona) > 40 {
+				persona = persona[:40] + "..."
+			}
+
+			allow := strings.Join(account.Allow, ",")
+			if len(allow) == 0 {
+				allow = "(everyone)"
+			}
+
+			piiSettings := "(default)"
+			if len(account.PIIRedaction)+len(account.PIIRestore)+len(account.PIIDetectors) > 0 {
+				piiSettings = "redaction=" + accountDefault(account.PIIRedaction) + " restore=" + accountDefault(account.PIIRestore) +
+					" detectors=" + accountDefault(strings.Join(account.PIIDetectors, ","))
+			}
+
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", device.ID.ToNonAD().String(), tag, strings.ReplaceAll(persona, "\n", " "), allow, piiSettings)
+		}
+
+		writer.Flush()
+	},
+}
+
+// AccountSet Variable Structure
+var AccountSet = &cobra.Command{
+	Use:   "set <jid or phone number>",
+	Short: "Set trigger tag, persona, allowed chats and PII redaction of a WhatsApp account",
+	Long: "Set Trigger Tag, Persona, Allowed Chats and PII Redaction of a WhatsApp Account of Go WhatsApp Multi-Device GPT, " +
+		"Running Daemon Applies The Settings on SIGHUP or '/reload' Admin Command",
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		device, err := findDevice(args[0])
+		if err != nil {
+			log.WithError(err).Println(log.LogLevelError, "Failed to Find WhatsApp Account")
+			return
+		}
+
+		account, err := pkgDatastore.GetAccount(context.Background(), device.ID.ToNonAD().String())
+		if err != nil {
+			log.WithError(err).Println(log.LogLevelError, "Failed to Load WhatsApp Account Settings from Datastore")
+			return
+		}
+
+		if cmd.Flags().Changed("tag") {
+			account.Tag = strings.TrimSpace(accountTag)
+		}
+
+		if cmd.Flags().Changed("persona") {
+			account.Persona = strings.TrimSpace(accountPersona)
+		}
+
+		if cmd.Flags().Changed("allow") {
+			account.Allow = nil
+
+			for _, allow := range accountAllow {
+				allowJID, err := pkgWhatsApp.WhatsAppParseJID(allow)
+				if err != nil {
+					log.WithFields(log.Fields{"allow": allow}).Println(log.LogLevelError, "Invalid Allowed JID or Phone Number")
+					return
+				}
+
+				account.Allow = append(account.Allow, allowJID.ToNonAD().String())
+			}
+		}
+
+		for _, flag := range []struct {
+			Name  string
+			Value string
+			Field *string
+		}{
+			{Name: "pii-redaction", Value: accountPIIRedaction, Field: &account.PIIRedaction},
+			{Name: "pii-restore", Value: accountPIIRestore, Field: &account.PIIRestore},
+		} {
+			if !cmd.Flags().Changed(flag.Name) {
+				continue
+			}
+
+			value := strings.ToLower(strings.TrimSpace(flag.Value))
+			if len(value) > 0 {
+				isEnabled, err := strconv.ParseBool(value)
+				if err != nil {
+					log.WithFields(log.Fields{flag.Name: flag.Value}).Println(log.LogLevelError, "Invalid PII Setting, Use true, false or Empty for Global Setting")
+					return
+				}
+
+				value = strconv.FormatBool(isEnabled)
+			}
+
+			*flag.Field = value
+		}
+
+		if cmd.Flags().Changed("pii-detectors") {
+			account.PIIDetectors = nil
+
+			for _, detector := range accountPIIDetectors {
+				detector = strings.ToLower(strings.TrimSpace(detector))
+				if !pii.IsDetector(detector) {
+					log.WithFields(log.Fields{"detector": detector}).Println(log.LogLevelError, "Unknown PII Detector, Use "+strings.Join(pii.Detectors, ", "))
+					return
+				}
+
+				account.PIIDetectors = append(account.PIIDetectors, detector)
+			}
+		}
+
+		account.UpdatedAt = time.Time{}
+
+		err = pkgDatastore.SaveAccount(context.Background(), account)
+		if err != nil {
+			log.WithError(err).Println(log.LogLevelError, "Failed to Save WhatsApp Account Settings to Datastore")
+			return
+		}
+
+		log.WithFields(log.Fields{"account": account.AccountJID}).Println(log.LogLevelInfo, "Successfully Saved WhatsApp Account Settings")
+	},
+}
+
+// AccountReset Variable Structure
+var AccountReset = &cobra.Command{
+	Use:   "reset <jid or phone number>",
+	Short: "Reset a WhatsApp account to use the global settings",
+	Long:  "Reset a WhatsApp Account of Go WhatsApp Multi-Device GPT to Use The Global Settings",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		device, err := findDevice(args[0])
+		if err != nil {
+			log.WithError(err).Println(log.LogLevelError, "Failed to Find WhatsApp Account")
+			return
+		}
+
+		err = pkgDatastore.DeleteAccount(context.Background(), device.ID.ToNonAD().String())
+		if err != nil {
+			log.WithError(err).Println(log.LogLevelError, "Failed to Reset WhatsApp Account Settings")
+			return
+		}
+
+		log.WithFields(log.Fields{"account": device.ID.ToNonAD().String()}).Println(log.LogLevelInfo, "Successfully Reset WhatsApp Account Settings")
+	},
+}
+
+func accountDefault(value string) string {
+	if len(value) == 0 {
+		return "(default)"
+	}
+
+	return value
+}
+
+// findDevice returns the logged-in device matching the JID or phone number,
+// or the only logged-in device when the value is empty.
+func findDevice(value string) (*store.Device, error) {
+	devices, err := pkgWhatsApp.WhatsAppDatastore.GetAllDevices(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(strings.TrimSpace(value)) == 0 {
+		switch len(devices) {
+		case 0:
+			return nil, errors.New("No Logged-in WhatsApp Account")
+		case 1:
+			return devices[0], nil
+		default:
+			return nil, errors.New("Multiple WhatsApp Accounts are Logged-in, Please Specify The Account")
+		}
+	}
+
+	jid, err := pkgWhatsApp.WhatsAppParseJID(value)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, device := range devices {
+		if device.ID.User == jid.User {
+			return device, nil
+		}
+	}
+
+	return nil, errors.New("WhatsApp Account '" + value + "' is not Logged-in")
+}
+
+func init() {
+	AccountSet.Flags().StringVar(&accountTag, "tag", "", "Trigger tag of the account, empty to use the global tag")
+	AccountSet.Flags().StringVar(&accountPersona, "persona", "", "System prompt of the account, empty to use the global system prompt")
+	AccountSet.Flags().StringSliceVar(&accountAllow, "allow", nil, "Allowed chat or sender JIDs or phone numbers, empty to allow everyone")
+	AccountSet.Flags().StringVar(&accountPIIRedaction, "pii-redaction", "", "Redact personal data of the account (true, false), empty to use the global setting")
+	AccountSet.Flags().StringVar(&accountPIIRestore, "pii-restore", "", "Restore redacted personal data in answers of the account (true, false), empty to use the global setting")
+	AccountSet.Flags().StringSliceVar(&accountPIIDetectors, "pii-detectors", nil, "PII detectors of the account (email, nik, phone, account), empty to use the global detectors")
+
+	Account.AddCommand(AccountList)
+	Account.AddCommand(AccountSet)
+	Account.AddCommand(AccountReset)
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	pkgDatastore "github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/datastore"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/log"
+	pkgWhatsApp "github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/whatsapp"
+)
+
+var auditFilter pkgDatastore.AuditFilter
+
+var (
+	auditSince,
+	auditUntil string
+)
+
+// Audit Variable Structure
+var Audit = &cobra.Command{
+	Use:   "audit",
+	Short: "Show moderation audit log",
+	Long:  "Show Moderation Audit Log of Go WhatsApp Multi-Device GPT",
+	Run: func(cmd *cobra.Command, args []string) {
+		var err error
+
+		auditFilter.Since, err = parseAuditTime(auditSince)
+		if err != nil {
+			log.WithError(err).Println(log.LogLevelError, "Invalid Audit Since Value, Use Duration (e.g. 24h) or Date (e.g. 2006-01-02)")
+			return
+		}
+
+		auditFilter.Until, err = parseAuditTime(auditUntil)
+		if err != nil {
+			log.WithError(err).Println(log.LogLevelError, "Invalid Audit Until Value, Use Duration (e.g. 24h) or Date (e.g. 2006-01-02)")
+			return
+		}
+
+		// Senders are Recorded without Device, So Phone Number Can be Used
+		if len(auditFilter.SenderJID) > 0 {
+			senderJID, err := pkgWhatsApp.WhatsAppParseJID(auditFilter.SenderJID)
+			if err != nil {
+				log.WithError(err).Println(log.LogLevelError, "Invalid Audit Sender Value, Use JID or Phone Number")
+				return
+			}
+
+			auditFilter.SenderJID = senderJID.ToNonAD().String()
+		}
+
+		entries, err := pkgDatastore.ListAudit(context.Background(), auditFilter)
+		if err != nil {
+			log.WithError(err).Println(log.LogLevelError, "Failed to Load Audit Entries from Datastore")
+			return
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "TIME\tCHAT\tSENDER\tREASON\tACTION\tDETAIL")
+
+		for _, entry := range entries {
+			fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n",
+				entry.CreatedAt.Format("2006-01-02 15:04:05"),
+				entry.ChatJID, entry.SenderJID, entry.Reason, entry.Action, entry.Detail)
+		}
+
+		writer.Flush()
+	},
+}
+
+// parseAuditTime accepts a duration relative to now (e.g. 24h)
+// or an absolute date and time.
+func parseAuditTime(value string) (time.Time, error) {
+	if len(value) == 0 {
+		return time.Time{}, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err == nil {
+		return time.Now().Add(-duration), nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"} {
+		parsed, err := time.ParseInLocation(layout, value, time.Local)
+		if err == nil {
+			return parsed, nil
+		}
+	}
+
+	return time.Time{}, err
+}
+
+func init() {
+	Audit.Flags().StringVar(&auditFilter.ChatJID, "chat", "", "Filter by chat JID")
+	Audit.Flags().StringVar(&auditFilter.SenderJID, "sender", "", "Filter by sender JID or phone number")
+	Audit.Flags().StringVar(&auditFilter.Reason, "reason", "", "Filter by reason (blocked_word, blocked_word_answer, moderation, moderation_answer, acl, rate_limit, ban)")
+	Audit.Flags().StringVar(&auditSince, "since", "", "Show entries since duration ago (e.g. 24h) or date (e.g. 2006-01-02)")
+	Audit.Flags().StringVar(&auditUntil, "until", "", "Show entries until duration ago (e.g. 1h) or date (e.g. 2006-01-02)")
+	Audit.Flags().IntVar(&auditFilter.Limit, "limit", 50, "Maximum number of entries to show, 0 for unlimited")
+}
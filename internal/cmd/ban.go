@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	pkgDatastore "github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/datastore"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/log"
+	pkgWhatsApp "github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/whatsapp"
+)
+
+var banListAll bool
+
+// Ban Variable Structure
+var Ban = &cobra.Command{
+	Use:   "ban",
+	Short: "Manage banned senders",
+	Long:  "Manage Banned Senders of Go WhatsApp Multi-Device GPT",
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// BanList Variable Structure
+var BanList = &cobra.Command{
+	Use:   "list",
+	Short: "List banned senders",
+	Long:  "List Banned Senders of Go WhatsApp Multi-Device GPT",
+	Run: func(cmd *cobra.Command, args []string) {
+		bans, err := pkgDatastore.ListBan(context.Background(), !banListAll)
+		if err != nil {
+			log.WithError(err).Println(log.LogLevelError, "Failed to Load Sender Bans from Datastore")
+			return
+		}
+
+		writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(writer, "SENDER\tSTRIKES\tBANS\tBANNED UNTIL\tBLOCKED")
+
+		for _, ban := range bans {
+			fmt.Fprintf(writer, "%s\t%d\t%d\t%s\t%t\n",
+				ban.SenderJID, ban.Strikes, ban.BanCount,
+				ban.BannedUntil.Format("2006-01-02 15:04:05"), ban.IsBlocked)
+		}
+
+		writer.Flush()
+	},
+}
+
+// BanLift Variable Structure
+var BanLift = &cobra.Command{
+	Use:   "lift <jid or phone number>",
+	Short: "Lift ban of a sender",
+	Long:  "Lift Ban of a Sender of Go WhatsApp Multi-Device GPT, Sender Blocked on WhatsApp Has to be Lifted with '/unban' Admin Command",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		senderJID, err := pkgWhatsApp.WhatsAppParseJID(args[0])
+		if err != nil {
+			log.WithFields(log.Fields{log.FieldSender: args[0]}).Println(log.LogLevelError, "Invalid Sender JID or Phone Number")
+			return
+		}
+
+		logFields := log.Fields{log.FieldSender: pkgWhatsApp.WhatsAppMaskJID(senderJID)}
+
+		// Ban Can be Recorded Under Either The Phone Number or The LID
+		senderJIDs := pkgWhatsApp.WhatsAppSenderJIDs(context.Background(), senderJID)
+
+		for _, jid := range senderJIDs {
+			ban, err := pkgDatastore.GetBan(context.Background(), jid.String())
+			if err != nil {
+				log.WithError(err).WithFields(logFields).Println(log.LogLevelError, "Failed to Load Sender Ban from Datastore")
+				return
+			}
+
+			// Blocked Sender Ban is Kept Until The Sender is Unblocked on WhatsApp
+			if ban.IsBlocked {
+				log.WithFields(logFields).Println(log.LogLevelError, "Sender is Blocked on WhatsApp, Use '/unban' Command as Admin to Lift The Ban and Unblock The Sender")
+				return
+			}
+		}
+
+		isLifted := false
+		for _, jid := range senderJIDs {
+			isDeleted, err := pkgDatastore.DeleteBan(context.Background(), jid.String())
+			if err != nil {
+				log.WithError(err).WithFields(logFields).Println(log.LogLevelError, "Failed to Lift Sender Ban")
+				return
+			}
+
+			isLifted = isLifted || isDeleted
+		}
+
+		if !isLifted {
+			log.WithFields(logFields).Println(log.LogLevelWarn, "There is No Ban for Sender")
+			return
+		}
+
+		log.WithFields(logFields).Println(log.LogLevelInfo, "Successfully Lifted Sender Ban")
+	},
+}
+
+func init() {
+	BanList.Flags().BoolVar(&banListAll, "all", false, "Show all senders including senders with strikes only")
+
+	Ban.AddCommand(BanList)
+	Ban.AddCommand(BanLift)
+}
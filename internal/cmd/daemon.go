@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
@@ -9,7 +10,10 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/filter"
 	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/log"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/server"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/tracing"
 	pkgWhatsApp "github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/whatsapp"
 )
 
@@ -24,46 +28,62 @@ var Daemon = &cobra.Command{
 		sig := make(chan os.Signal, 1)
 		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
 
-		isHandlerOn := false
-		time.Sleep(time.Duration(1) * time.Second)
+		// Reload Configuration Without Dropping WhatsApp Connection
+		reload := make(chan os.Signal, 1)
+		signal.Notify(reload, syscall.SIGHUP)
+		go func() {
+			for range reload {
+				_, err := pkgWhatsApp.WhatsAppReloadConfig()
+				if err != nil {
+					log.WithError(err).Println(log.LogLevelError, "Failed to Reload Configuration, Keeping Current Configuration")
+				}
+			}
+		}()
 
-		for {
-			if pkgWhatsApp.WhatsAppClient != nil {
-				if pkgWhatsApp.WhatsAppClient.IsConnected() && !isHandlerOn {
-					log.Println(log.LogLevelInfo, "Starting WhatsApp Client Event Listener for OpenAI GPT")
-					pkgWhatsApp.WhatsAppClient.AddEventHandler(pkgWhatsApp.WhatsAppHandler)
+		stopWatcher := make(chan struct{})
+		go filter.Watch(stopWatcher)
 
-					isHandlerOn = true
-				} else if !pkgWhatsApp.WhatsAppClient.IsConnected() {
-					log.Println(log.LogLevelWarn, "WhatsApp Client Connection Interupted, Wait 3s for Reloading")
+		pkgWhatsApp.WhatsAppQueueStart(pkgWhatsApp.WhatsAppGPTQueueWorker)
 
-					pkgWhatsApp.WhatsAppClient.RemoveEventHandlers()
-					pkgWhatsApp.WhatsAppClient.Disconnect()
-					ReloadDatastore()
+		httpServer := server.Start()
 
-					time.Sleep(time.Duration(3) * time.Second)
-					isHandlerOn = false
-				}
-			} else {
-				log.Println(log.LogLevelWarn, "Waiting for WhatsApp Client to be Logged-in")
-				ReloadDatastore()
+		tracing.Start(context.Background())
 
-				isHandlerOn = false
-			}
+		// Keep Every WhatsApp Account Connected Based on Connection Events
+		stopSupervisor := make(chan struct{})
+		doneSupervisor := make(chan struct{})
+		go func() {
+			pkgWhatsApp.WhatsAppSupervise(stopSupervisor)
+			close(doneSupervisor)
+		}()
 
-			select {
-			case <-sig:
-				fmt.Println("")
+		<-sig
+		fmt.Println("")
 
-				if pkgWhatsApp.WhatsAppClient != nil {
-					pkgWhatsApp.WhatsAppClient.RemoveEventHandlers()
-					pkgWhatsApp.WhatsAppClient.Disconnect()
-				}
+		signal.Stop(reload)
+
+		// Stop Accepting New Questions and Wait for In-Flight Questions
+		pkgWhatsApp.WhatsAppQueueStop(time.Duration(pkgWhatsApp.WhatsAppGPTShutdownTimeout) * time.Second)
 
-				log.Println(log.LogLevelInfo, "Terminating Process")
-				os.Exit(0)
-			case <-time.After(5 * time.Second):
+		for _, account := range pkgWhatsApp.WhatsAppAccounts() {
+			if account.Client.IsConnected() {
+				pkgWhatsApp.WhatsAppPresence(account, false)
 			}
 		}
+
+		close(stopSupervisor)
+		<-doneSupervisor
+
+		for _, account := range pkgWhatsApp.WhatsAppAccounts() {
+			account.Client.RemoveEventHandlers()
+			account.Client.Disconnect()
+		}
+
+		close(stopWatcher)
+		server.Stop(httpServer)
+		tracing.Stop()
+
+		log.Println(log.LogLevelInfo, "Terminating Process")
+		os.Exit(0)
 	},
 }
@@ -0,0 +1,257 @@
+package cmd
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.mau.fi/whatsmeow/types"
+
+	pkgDatastore "github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/datastore"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/log"
+	pkgWhatsApp "github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/whatsapp"
+)
+
+const (
+	ExportFormatJSONL    string = "jsonl"
+	ExportFormatMarkdown string = "markdown"
+	ExportFormatCSV      string = "csv"
+)
+
+var exportFilter pkgDatastore.ConversationFilter
+
+var (
+	exportAccount,
+	exportChat,
+	exportSince,
+	exportUntil,
+	exportFormat,
+	exportOutput string
+)
+
+// Export Variable Structure
+var Export = &cobra.Command{
+	Use:   "export",
+	Short: "Export conversation transcripts",
+	Long:  "Export Conversation Transcripts of Go WhatsApp Multi-Device GPT",
+	Run: func(cmd *cobra.Command, args []string) {
+		var err error
+
+		if len(exportChat) > 0 {
+			chatJID, err := pkgWhatsApp.WhatsAppParseJID(exportChat)
+			if err != nil {
+				log.WithError(err).Println(log.LogLevelError, "Invalid Export Chat Value, Use JID or Phone Number")
+				return
+			}
+
+			exportFilter.ChatJID = chatJID.String()
+		}
+
+		if len(exportAccount) > 0 {
+			accountJID, err := pkgWhatsApp.WhatsAppParseJID(exportAccount)
+			if err != nil {
+				log.WithError(err).Println(log.LogLevelError, "Invalid Export Account Value, Use JID or Phone Number")
+				return
+			}
+
+			exportFilter.AccountJID = accountJID.ToNonAD().String()
+		}
+
+		exportFilter.Since, err = parseAuditTime(exportSince)
+		if err != nil {
+			log.WithError(err).Println(log.LogLevelError, "Invalid Export Since Value, Use Duration (e.g. 24h) or Date (e.g. 2006-01-02)")
+			return
+		}
+
+		exportFilter.Until, err = parseAuditTime(exportUntil)
+		if err != nil {
+			log.WithError(err).Println(log.LogLevelError, "Invalid Export Until Value, Use Duration (e.g. 1h) or Date (e.g. 2006-01-02)")
+			return
+		}
+
+		var write func(io.Writer, []pkgDatastore.Conversation) error
+
+		switch strings.ToLower(exportFormat) {
+		case ExportFormatJSONL:
+			write = exportJSONL
+		case ExportFormatMarkdown, "md":
+			write = exportMarkdown
+		case ExportFormatCSV:
+			write = exportCSV
+		default:
+			log.WithFields(log.Fields{"format": exportFormat}).Println(log.LogLevelError, "Invalid Export Format, Use jsonl, markdown or csv")
+			return
+		}
+
+		conversations, err := pkgDatastore.ListConversation(context.Background(), exportFilter)
+		if err != nil {
+			log.WithError(err).Println(log.LogLevelError, "Failed to Load Conversations from Datastore")
+			return
+		}
+
+		output := os.Stdout
+		if len(exportOutput) > 0 && exportOutput != "-" {
+			output, err = os.Create(exportOutput)
+			if err != nil {
+				log.WithError(err).Println(log.LogLevelError, "Failed to Create Export Output File")
+				return
+			}
+			defer output.Close()
+		}
+
+		err = write(output, conversations)
+		if err != nil {
+			log.WithError(err).Println(log.LogLevelError, "Failed to Write Conversation Transcripts")
+			return
+		}
+
+		if output != os.Stdout {
+			log.WithFields(log.Fields{"count": len(conversations), "file": exportOutput}).Println(log.LogLevelInfo, "Exported Conversation Transcripts")
+		}
+	},
+}
+
+type exportRecord struct {
+	AccountJID       string `json:"account_jid"`
+	ChatJID          string `json:"chat_jid"`
+	MessageID        string `json:"message_id"`
+	SenderJID        string `json:"sender_jid"`
+	Question         string `json:"question"`
+	Answer           string `json:"answer"`
+	Status           string `json:"status"`
+	Model            string `json:"model"`
+	PromptTokens     *int   `json:"prompt_tokens"`
+	CompletionTokens *int   `json:"completion_tokens"`
+	SentAt           string `json:"sent_at"`
+	AnsweredAt       string `json:"answered_at"`
+}
+
+func exportJSONL(writer io.Writer, conversations []pkgDatastore.Conversation) error {
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+
+	for _, conversation := range conversations {
+		// Unknown Token Usage is Exported as Null Instead of Zero
+		var promptTokens, completionTokens *int
+		if conversation.IsUsageReported() {
+			promptTokens, completionTokens = &conversation.PromptTokens, &conversation.CompletionTokens
+		}
+
+		err := encoder.Encode(exportRecord{
+			AccountJID:       exportMaskJID(conversation.AccountJID),
+			ChatJID:          exportMaskJID(conversation.ChatJID),
+			MessageID:        conversation.MessageID,
+			SenderJID:        exportMaskJID(conversation.SenderJID),
+			Question:         conversation.Question,
+			Answer:           conversation.Answer,
+			Status:           conversation.Status,
+			Model:            conversation.Model,
+			PromptTokens:     promptTokens,
+			CompletionTokens: completionTokens,
+			SentAt:           conversation.SentAt.Format(time.RFC3339),
+			AnsweredAt:       conversation.AnsweredAt.Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func exportCSV(writer io.Writer, conversations []pkgDatastore.Conversation) error {
+	csvWriter := csv.NewWriter(writer)
+
+	err := csvWriter.Write([]string{"account_jid", "chat_jid", "message_id", "sender_jid", "question", "answer", "status",
+		"model", "prompt_tokens", "completion_tokens", "sent_at", "answered_at"})
+	if err != nil {
+		return err
+	}
+
+	for _, conversation := range conversations {
+		var promptTokens, completionTokens string
+		if conversation.IsUsageReported() {
+			promptTokens, completionTokens = strconv.Itoa(conversation.PromptTokens), strconv.Itoa(conversation.CompletionTokens)
+		}
+
+		err = csvWriter.Write([]string{
+			exportMaskJID(conversation.AccountJID), exportMaskJID(conversation.ChatJID), conversation.MessageID, exportMaskJID(conversation.SenderJID),
+			conversation.Question, conversation.Answer, conversation.Status, conversation.Model,
+			promptTokens, completionTokens,
+			conversation.SentAt.Format(time.RFC3339), conversation.AnsweredAt.Format(time.RFC3339),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// exportMaskJID masks the stored JID the same way as the logs, a value which
+// is not a valid JID is returned as is.
+func exportMaskJID(value string) string {
+	jid, err := types.ParseJID(value)
+	if err != nil || jid.IsEmpty() {
+		return value
+	}
+
+	return pkgWhatsApp.WhatsAppMaskJID(jid)
+}
+
+// exportMarkdown writes one section per chat, conversations are already
+// ordered by chat from the datastore.
+func exportMarkdown(writer io.Writer, conversations []pkgDatastore.Conversation) error {
+	var builder strings.Builder
+	var chatJID string
+
+	builder.WriteString("# Conversation Transcripts\n")
+
+	for _, conversation := range conversations {
+		if conversation.ChatJID != chatJID {
+			chatJID = conversation.ChatJID
+			fmt.Fprintf(&builder, "\n## %s\n", exportMaskJID(chatJID))
+		}
+
+		fmt.Fprintf(&builder, "\n### %s - %s\n\n", conversation.SentAt.Format("2006-01-02 15:04:05"), exportMaskJID(conversation.SenderJID))
+
+		if len(conversation.AccountJID) > 0 {
+			fmt.Fprintf(&builder, "_Account: %s_\n\n", exportMaskJID(conversation.AccountJID))
+		}
+		fmt.Fprintf(&builder, "**Question:**\n\n%s\n\n", exportQuote(conversation.Question))
+		fmt.Fprintf(&builder, "**Answer** (%s, %s):\n\n%s\n\n", conversation.Status, conversation.AnsweredAt.Format("2006-01-02 15:04:05"), exportQuote(conversation.Answer))
+
+		switch {
+		case len(conversation.Model) > 0 && conversation.IsUsageReported():
+			fmt.Fprintf(&builder, "_Model: %s, Prompt Tokens: %d, Completion Tokens: %d_\n",
+				conversation.Model, conversation.PromptTokens, conversation.CompletionTokens)
+		case len(conversation.Model) > 0:
+			fmt.Fprintf(&builder, "_Model: %s_\n", conversation.Model)
+		}
+	}
+
+	_, err := io.WriteString(writer, builder.String())
+	return err
+}
+
+func exportQuote(text string) string {
+	return "> " + strings.ReplaceAll(text, "\n", "\n> ")
+}
+
+func init() {
+	Export.Flags().StringVar(&exportAccount, "account", "", "Filter by answering account JID or phone number")
+	Export.Flags().StringVar(&exportChat, "chat", "", "Filter by chat JID or phone number")
+	Export.Flags().StringVar(&exportSince, "since", "", "Export conversations since duration ago (e.g. 24h) or date (e.g. 2006-01-02)")
+	Export.Flags().StringVar(&exportUntil, "until", "", "Export conversations until duration ago (e.g. 1h) or date (e.g. 2006-01-02)")
+	Export.Flags().StringVar(&exportFormat, "format", ExportFormatJSONL, "Export format (jsonl, markdown, csv)")
+	Export.Flags().StringVarP(&exportOutput, "output", "o", "", "Write export to file instead of standard output")
+	Export.Flags().IntVar(&exportFilter.Limit, "limit", 0, "Maximum number of conversations to export, 0 for unlimited")
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -15,44 +16,55 @@ import (
 
 // Login Variable Structure
 var Login = &cobra.Command{
-	Use:   "login",
-	Short: "Login to Go WhatsApp Multi-Device GPT",
-	Long:  "Login to Go WhatsApp Multi-Device GPT",
+	Use:   "login [phone number]",
+	Short: "Login a WhatsApp account to Go WhatsApp Multi-Device GPT",
+	Long:  "Login a WhatsApp Account to Go WhatsApp Multi-Device GPT, Each Login Adds Another Account",
+	Args:  cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		log.Println(log.LogLevelInfo, "Go WhatsApp Multi-Device GPT")
 
-		ReloadDatastore()
-		if pkgWhatsApp.WhatsAppClient == nil {
-			pkgWhatsApp.WhatsAppInitClient(nil)
+		var phoneNumber string
 
+		if len(args) > 0 {
+			phoneNumber = args[0]
+		} else {
 			fmt.Println("")
 			fmt.Println("Please Insert Your Phone Number to Generate Pair Code:")
 
 			phoneInput := bufio.NewReader(os.Stdin)
-			phoneNumber, err := phoneInput.ReadString('\n')
-			if err != nil {
-				log.Println(log.LogLevelError, "Failed to Get Phone Number Input!")
-				return
-			}
 
-			pairResponse, pairTimeout, err := pkgWhatsApp.WhatsAppLogin(phoneNumber)
+			var err error
+			phoneNumber, err = phoneInput.ReadString('\n')
 			if err != nil {
-				log.Println(log.LogLevelError, err.Error())
+				log.WithError(err).Println(log.LogLevelError, "Failed to Get Phone Number Input!")
 				return
 			}
+		}
 
-			if pairResponse == "WhatsApp Client is Reconnected" {
-				log.Println(log.LogLevelInfo, pairResponse)
-				return
-			}
+		phoneNumber = strings.TrimPrefix(strings.TrimSpace(phoneNumber), "+")
+		if len(phoneNumber) == 0 {
+			log.Println(log.LogLevelError, "Phone Number is Required to Generate Pair Code")
+			return
+		}
 
-			fmt.Println("")
-			log.Println(log.LogLevelInfo, "Successfully Generate Pair Code. Your Pair Code is "+pairResponse)
-			log.Println(log.LogLevelInfo, "Pair Code Will Be Expired in "+strconv.Itoa(pairTimeout)+"s")
+		device, err := findDevice(phoneNumber)
+		if err == nil {
+			log.WithFields(log.Fields{"account": pkgWhatsApp.WhatsAppMaskJID(device.ID.ToNonAD())}).Println(log.LogLevelInfo, "WhatsApp Client Already Logged-in")
+			return
+		}
 
-			time.Sleep(time.Duration(pairTimeout) * time.Second)
-		} else {
-			log.Println(log.LogLevelInfo, "WhatsApp Client Already Logged-in")
+		client := pkgWhatsApp.WhatsAppNewClient(nil)
+
+		pairResponse, pairTimeout, err := pkgWhatsApp.WhatsAppLogin(client, phoneNumber)
+		if err != nil {
+			log.WithError(err).Println(log.LogLevelError, "Failed to Login WhatsApp Client")
+			return
 		}
+
+		fmt.Println("")
+		log.WithFields(log.Fields{"pair_code": pairResponse, "expires_in": strconv.Itoa(pairTimeout) + "s"}).Println(log.LogLevelInfo, "Successfully Generate Pair Code")
+
+		time.Sleep(time.Duration(pairTimeout) * time.Second)
+		client.Disconnect()
 	},
 }
@@ -9,18 +9,52 @@ import (
 
 // Logout Variable Structure
 var Logout = &cobra.Command{
-	Use:   "logout",
-	Short: "Logout from Go WhatsApp Multi-Device GPT",
-	Long:  "Logout from Go WhatsApp Multi-Device GPT",
+	Use:   "logout [jid or phone number]",
+	Short: "Logout a WhatsApp account from Go WhatsApp Multi-Device GPT",
+	Long: "Logout a WhatsApp Account from Go WhatsApp Multi-Device GPT, The Account Can be Omitted When Only One Account is Logged-in, " +
+		"Use '/logout' Admin Command Instead While The Daemon is Running",
+	Args: cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		log.Println(log.LogLevelInfo, "Go WhatsApp Multi-Device GPT")
 
-		ReloadDatastore()
-		if pkgWhatsApp.WhatsAppClient != nil {
-			pkgWhatsApp.WhatsAppClient.RemoveEventHandlers()
-			pkgWhatsApp.WhatsAppLogout()
+		// Running Daemon is Holding The Session, Connecting Another Client Would Replace It
+		isRunning, err := pkgWhatsApp.WhatsAppDaemonIsRunning()
+		if err != nil {
+			log.WithError(err).Println(log.LogLevelError, "Failed to Check Running Daemon in Datastore")
+			return
 		}
 
-		log.Println(log.LogLevelInfo, "Successfully Logged-out WhatsApp Client")
+		if isRunning {
+			log.Println(log.LogLevelError, "Daemon is Running, Use '/logout' Admin Command to The Account or Stop The Daemon First")
+			return
+		}
+
+		account := ""
+		if len(args) > 0 {
+			account = args[0]
+		}
+
+		device, err := findDevice(account)
+		if err != nil {
+			log.WithError(err).Println(log.LogLevelError, "Failed to Find WhatsApp Account")
+			return
+		}
+
+		logEntry := log.WithFields(log.Fields{"account": pkgWhatsApp.WhatsAppMaskJID(device.ID.ToNonAD())})
+
+		client := pkgWhatsApp.WhatsAppNewClient(device)
+
+		err = pkgWhatsApp.WhatsAppReconnect(client)
+		if err != nil {
+			logEntry.WithError(err).Println(log.LogLevelWarn, "Failed to Connect WhatsApp Client, Removing Device from Datastore Only")
+		}
+
+		err = pkgWhatsApp.WhatsAppLogout(client)
+		if err != nil {
+			logEntry.WithError(err).Println(log.LogLevelError, "Failed to Logout WhatsApp Client")
+			return
+		}
+
+		logEntry.Println(log.LogLevelInfo, "Successfully Logged-out WhatsApp Client")
 	},
 }
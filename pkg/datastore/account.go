@@ -0,0 +1,118 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+)
+
+// Account is the per-account settings of a logged-in WhatsApp device.
+// Empty settings are using the global configuration.
+type Account struct {
+	AccountJID   string
+	Tag          string
+	Persona      string
+	Allow        []string
+	PIIRedaction string
+	PIIRestore   string
+	PIIDetectors []string
+	UpdatedAt    time.Time
+}
+
+func splitList(value string) []string {
+	if len(value) == 0 {
+		return nil
+	}
+
+	return strings.Split(value, ",")
+}
+
+func scanAccount(row interface{ Scan(...interface{}) error }) (Account, error) {
+	var account Account
+	var allow, piiDetectors string
+	var updatedAt int64
+
+	err := row.Scan(&account.AccountJID, &account.Tag, &account.Persona, &allow,
+		&account.PIIRedaction, &account.PIIRestore, &piiDetectors, &updatedAt)
+	if err != nil {
+		return Account{}, err
+	}
+
+	account.Allow = splitList(allow)
+	account.PIIDetectors = splitList(piiDetectors)
+	account.UpdatedAt = time.Unix(updatedAt, 0)
+
+	return account, nil
+}
+
+func GetAccount(ctx context.Context, accountJID string) (Account, error) {
+	account, err := scanAccount(DB.QueryRowContext(ctx,
+		`SELECT account_jid, tag, persona, allow, pii_redaction, pii_restore, pii_detectors, updated_at
+		FROM whatsapp_gpt_account WHERE account_jid = $1`,
+		accountJID,
+	))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return Account{AccountJID: accountJID}, nil
+	}
+
+	return account, err
+}
+
+func SaveAccount(ctx context.Context, account Account) error {
+	if account.UpdatedAt.IsZero() {
+		account.UpdatedAt = time.Now()
+	}
+
+	_, err := DB.ExecContext(ctx,
+		`INSERT INTO whatsapp_gpt_account (account_jid, tag, persona, allow, pii_redaction, pii_restore, pii_detectors, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (account_jid) DO UPDATE SET
+			tag = excluded.tag,
+			persona = excluded.persona,
+			allow = excluded.allow,
+			pii_redaction = excluded.pii_redaction,
+			pii_restore = excluded.pii_restore,
+			pii_detectors = excluded.pii_detectors,
+			updated_at = excluded.updated_at`,
+		account.AccountJID, account.Tag, account.Persona, strings.Join(account.Allow, ","),
+		account.PIIRedaction, account.PIIRestore, strings.Join(account.PIIDetectors, ","), account.UpdatedAt.Unix(),
+	)
+
+	return err
+}
+
+func DeleteAccount(ctx context.Context, accountJID string) error {
+	_, err := DB.ExecContext(ctx,
+		`DELETE FROM whatsapp_gpt_account WHERE account_jid = $1`,
+		accountJID,
+	)
+
+	return err
+}
+
+func ListAccount(ctx context.Context) ([]Account, error) {
+	rows, err := DB.QueryContext(ctx,
+		`SELECT account_jid, tag, persona, allow, pii_redaction, pii_restore, pii_detectors, updated_at
+		FROM whatsapp_gpt_account ORDER BY account_jid`,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accounts []Account
+
+	for rows.Next() {
+		account, err := scanAccount(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		accounts = append(accounts, account)
+	}
+
+	return accounts, rows.Err()
+}
@@ -0,0 +1,113 @@
+package datastore
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	AuditReasonBlockedWord       string = "blocked_word"
+	AuditReasonBlockedWordAnswer string = "blocked_word_answer"
+	AuditReasonModeration        string = "moderation"
+	AuditReasonModerationAnswer  string = "moderation_answer"
+	AuditReasonACL               string = "acl"
+	AuditReasonRateLimit         string = "rate_limit"
+	AuditReasonBan               string = "ban"
+)
+
+type AuditEntry struct {
+	ChatJID   string
+	SenderJID string
+	Reason    string
+	Action    string
+	Detail    string
+	CreatedAt time.Time
+}
+
+type AuditFilter struct {
+	ChatJID   string
+	SenderJID string
+	Reason    string
+	Since     time.Time
+	Until     time.Time
+	Limit     int
+}
+
+func InsertAudit(ctx context.Context, entry AuditEntry) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	_, err := DB.ExecContext(ctx,
+		`INSERT INTO whatsapp_gpt_audit (chat_jid, sender_jid, reason, action, detail, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		entry.ChatJID, entry.SenderJID, entry.Reason, entry.Action, entry.Detail, entry.CreatedAt.Unix(),
+	)
+
+	return err
+}
+
+func ListAudit(ctx context.Context, filter AuditFilter) ([]AuditEntry, error) {
+	var conditions []string
+	var args []interface{}
+
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, condition+" $"+strconv.Itoa(len(args)))
+	}
+
+	if len(filter.ChatJID) > 0 {
+		addCondition("chat_jid =", filter.ChatJID)
+	}
+
+	if len(filter.SenderJID) > 0 {
+		addCondition("sender_jid =", filter.SenderJID)
+	}
+
+	if len(filter.Reason) > 0 {
+		addCondition("reason =", filter.Reason)
+	}
+
+	if !filter.Since.IsZero() {
+		addCondition("created_at >=", filter.Since.Unix())
+	}
+
+	if !filter.Until.IsZero() {
+		addCondition("created_at <=", filter.Until.Unix())
+	}
+
+	query := `SELECT chat_jid, sender_jid, reason, action, detail, created_at FROM whatsapp_gpt_audit`
+	if len(conditions) > 0 {
+		query = query + " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query = query + " ORDER BY created_at DESC"
+	if filter.Limit > 0 {
+		query = query + " LIMIT " + strconv.Itoa(filter.Limit)
+	}
+
+	rows, err := DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []AuditEntry
+
+	for rows.Next() {
+		var entry AuditEntry
+		var createdAt int64
+
+		err = rows.Scan(&entry.ChatJID, &entry.SenderJID, &entry.Reason, &entry.Action, &entry.Detail, &createdAt)
+		if err != nil {
+			return nil, err
+		}
+
+		entry.CreatedAt = time.Unix(createdAt, 0)
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
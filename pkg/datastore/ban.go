@@ -0,0 +1,119 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+type Ban struct {
+	SenderJID    string
+	Strikes      int
+	BanCount     int
+	BannedUntil  time.Time
+	LastStrikeAt time.Time
+	IsBlocked    bool
+}
+
+func (ban Ban) IsBanned() bool {
+	return ban.IsBlocked || time.Now().Before(ban.BannedUntil)
+}
+
+func scanBan(row interface{ Scan(...interface{}) error }) (Ban, error) {
+	var ban Ban
+	var bannedUntil, lastStrikeAt int64
+	var isBlocked int
+
+	err := row.Scan(&ban.SenderJID, &ban.Strikes, &ban.BanCount, &bannedUntil, &lastStrikeAt, &isBlocked)
+	if err != nil {
+		return Ban{}, err
+	}
+
+	ban.BannedUntil = time.Unix(bannedUntil, 0)
+	ban.LastStrikeAt = time.Unix(lastStrikeAt, 0)
+	ban.IsBlocked = isBlocked != 0
+
+	return ban, nil
+}
+
+func GetBan(ctx context.Context, senderJID string) (Ban, error) {
+	ban, err := scanBan(DB.QueryRowContext(ctx,
+		`SELECT sender_jid, strikes, ban_count, banned_until, last_strike_at, is_blocked
+		FROM whatsapp_gpt_ban WHERE sender_jid = $1`,
+		senderJID,
+	))
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return Ban{SenderJID: senderJID}, nil
+	}
+
+	return ban, err
+}
+
+func SaveBan(ctx context.Context, ban Ban) error {
+	isBlocked := 0
+	if ban.IsBlocked {
+		isBlocked = 1
+	}
+
+	_, err := DB.ExecContext(ctx,
+		`INSERT INTO whatsapp_gpt_ban (sender_jid, strikes, ban_count, banned_until, last_strike_at, is_blocked)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (sender_jid) DO UPDATE SET
+			strikes = excluded.strikes,
+			ban_count = excluded.ban_count,
+			banned_until = excluded.banned_until,
+			last_strike_at = excluded.last_strike_at,
+			is_blocked = excluded.is_blocked`,
+		ban.SenderJID, ban.Strikes, ban.BanCount, ban.BannedUntil.Unix(), ban.LastStrikeAt.Unix(), isBlocked,
+	)
+
+	return err
+}
+
+func ListBan(ctx context.Context, isActiveOnly bool) ([]Ban, error) {
+	query := `SELECT sender_jid, strikes, ban_count, banned_until, last_strike_at, is_blocked FROM whatsapp_gpt_ban`
+
+	var args []interface{}
+	if isActiveOnly {
+		query = query + ` WHERE banned_until > $1 OR is_blocked <> 0`
+		args = append(args, time.Now().Unix())
+	}
+
+	rows, err := DB.QueryContext(ctx, query+` ORDER BY banned_until DESC`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bans []Ban
+
+	for rows.Next() {
+		ban, err := scanBan(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		bans = append(bans, ban)
+	}
+
+	return bans, rows.Err()
+}
+
+func DeleteBan(ctx context.Context, senderJID string) (bool, error) {
+	result, err := DB.ExecContext(ctx,
+		`DELETE FROM whatsapp_gpt_ban WHERE sender_jid = $1`,
+		senderJID,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
@@ -0,0 +1,134 @@
+package datastore
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ConversationStatusAnswered string = "answered"
+	ConversationStatusBlocked  string = "blocked"
+	ConversationStatusFailed   string = "failed"
+)
+
+// Conversation is a question and the answer sent for it, kept to be
+// exported as transcript.
+type Conversation struct {
+	AccountJID       string
+	ChatJID          string
+	MessageID        string
+	SenderJID        string
+	Question         string
+	Answer           string
+	Status           string
+	Model            string
+	PromptTokens     int
+	CompletionTokens int
+	SentAt           time.Time
+	AnsweredAt       time.Time
+}
+
+// IsUsageReported reports whether the token usage is reported by the
+// endpoint, every reported completion has prompt tokens so zero tokens
+// means the usage is unknown.
+func (conversation Conversation) IsUsageReported() bool {
+	return conversation.PromptTokens > 0
+}
+
+type ConversationFilter struct {
+	AccountJID string
+	ChatJID    string
+	Since      time.Time
+	Until      time.Time
+	Limit      int
+}
+
+func InsertConversation(ctx context.Context, conversation Conversation) error {
+	if conversation.AnsweredAt.IsZero() {
+		conversation.AnsweredAt = time.Now()
+	}
+
+	_, err := DB.ExecContext(ctx,
+		`INSERT INTO whatsapp_gpt_conversation (chat_jid, message_id, sender_jid, question, answer, status, model, prompt_tokens, completion_tokens, sent_at, answered_at, account_jid)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		ON CONFLICT (chat_jid, message_id) DO UPDATE SET
+			answer = excluded.answer,
+			status = excluded.status,
+			model = excluded.model,
+			prompt_tokens = excluded.prompt_tokens,
+			completion_tokens = excluded.completion_tokens,
+			answered_at = excluded.answered_at`,
+		conversation.ChatJID, conversation.MessageID, conversation.SenderJID, conversation.Question, conversation.Answer,
+		conversation.Status, conversation.Model, conversation.PromptTokens, conversation.CompletionTokens,
+		conversation.SentAt.Unix(), conversation.AnsweredAt.Unix(), conversation.AccountJID,
+	)
+
+	return err
+}
+
+// ListConversation returns conversations ordered by chat and time,
+// so each chat can be read as a single transcript.
+func ListConversation(ctx context.Context, filter ConversationFilter) ([]Conversation, error) {
+	var conditions []string
+	var args []interface{}
+
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, condition+" $"+strconv.Itoa(len(args)))
+	}
+
+	if len(filter.AccountJID) > 0 {
+		addCondition("account_jid =", filter.AccountJID)
+	}
+
+	if len(filter.ChatJID) > 0 {
+		addCondition("chat_jid =", filter.ChatJID)
+	}
+
+	if !filter.Since.IsZero() {
+		addCondition("answered_at >=", filter.Since.Unix())
+	}
+
+	if !filter.Until.IsZero() {
+		addCondition("answered_at <=", filter.Until.Unix())
+	}
+
+	query := `SELECT account_jid, chat_jid, message_id, sender_jid, question, answer, status, model, prompt_tokens, completion_tokens, sent_at, answered_at
+		FROM whatsapp_gpt_conversation`
+	if len(conditions) > 0 {
+		query = query + " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query = query + " ORDER BY chat_jid, sent_at, answered_at"
+	if filter.Limit > 0 {
+		query = query + " LIMIT " + strconv.Itoa(filter.Limit)
+	}
+
+	rows, err := DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var conversations []Conversation
+
+	for rows.Next() {
+		var conversation Conversation
+		var sentAt, answeredAt int64
+
+		err = rows.Scan(&conversation.AccountJID, &conversation.ChatJID, &conversation.MessageID, &conversation.SenderJID, &conversation.Question,
+			&conversation.Answer, &conversation.Status, &conversation.Model, &conversation.PromptTokens,
+			&conversation.CompletionTokens, &sentAt, &answeredAt)
+		if err != nil {
+			return nil, err
+		}
+
+		conversation.SentAt = time.Unix(sentAt, 0)
+		conversation.AnsweredAt = time.Unix(answeredAt, 0)
+		conversations = append(conversations, conversation)
+	}
+
+	return conversations, rows.Err()
+}
@@ -0,0 +1,43 @@
+package datastore
+
+import (
+	"context"
+	"time"
+)
+
+// SaveDaemonHeartbeat records the running daemon instance, so the commands
+// can tell whether a daemon is holding the WhatsApp sessions.
+func SaveDaemonHeartbeat(ctx context.Context, instance string) error {
+	_, err := DB.ExecContext(ctx,
+		`INSERT INTO whatsapp_gpt_daemon (instance, heartbeat_at) VALUES ($1, $2)
+		ON CONFLICT (instance) DO UPDATE SET heartbeat_at = excluded.heartbeat_at`,
+		instance, time.Now().Unix(),
+	)
+
+	return err
+}
+
+func DeleteDaemonHeartbeat(ctx context.Context, instance string) error {
+	_, err := DB.ExecContext(ctx,
+		`DELETE FROM whatsapp_gpt_daemon WHERE instance = $1`,
+		instance,
+	)
+
+	return err
+}
+
+// IsDaemonRunning reports whether any daemon instance has sent a heartbeat
+// since the given time.
+func IsDaemonRunning(ctx context.Context, since time.Time) (bool, error) {
+	var count int
+
+	err := DB.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM whatsapp_gpt_daemon WHERE heartbeat_at >= $1`,
+		since.Unix(),
+	).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -0,0 +1,150 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/env"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/log"
+)
+
+var DB *sql.DB
+
+var (
+	DBType,
+	DBURI string
+)
+
+var schemas = []string{
+	`CREATE TABLE IF NOT EXISTS whatsapp_gpt_chat_language (
+		chat_jid TEXT PRIMARY KEY,
+		language TEXT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS whatsapp_gpt_audit (
+		chat_jid   TEXT NOT NULL,
+		sender_jid TEXT NOT NULL,
+		reason     TEXT NOT NULL,
+		action     TEXT NOT NULL,
+		detail     TEXT NOT NULL,
+		created_at BIGINT NOT NULL
+	)`,
+	`CREATE INDEX IF NOT EXISTS whatsapp_gpt_audit_created_at_idx ON whatsapp_gpt_audit (created_at)`,
+	`CREATE TABLE IF NOT EXISTS whatsapp_gpt_processed_message (
+		chat_jid     TEXT NOT NULL,
+		message_id   TEXT NOT NULL,
+		status       TEXT NOT NULL DEFAULT 'done',
+		owner        TEXT NOT NULL DEFAULT '',
+		processed_at BIGINT NOT NULL,
+		PRIMARY KEY (chat_jid, message_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS whatsapp_gpt_ban (
+		sender_jid     TEXT PRIMARY KEY,
+		strikes        INTEGER NOT NULL,
+		ban_count      INTEGER NOT NULL,
+		banned_until   BIGINT NOT NULL,
+		last_strike_at BIGINT NOT NULL,
+		is_blocked     INTEGER NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS whatsapp_gpt_pending_question (
+		chat_jid        TEXT NOT NULL,
+		message_id      TEXT NOT NULL,
+		sender_jid      TEXT NOT NULL,
+		question        TEXT NOT NULL,
+		is_low_priority INTEGER NOT NULL,
+		sent_at         BIGINT NOT NULL,
+		created_at      BIGINT NOT NULL,
+		PRIMARY KEY (chat_jid, message_id)
+	)`,
+	`CREATE TABLE IF NOT EXISTS whatsapp_gpt_conversation (
+		chat_jid          TEXT NOT NULL,
+		message_id        TEXT NOT NULL,
+		sender_jid        TEXT NOT NULL,
+		question          TEXT NOT NULL,
+		answer            TEXT NOT NULL,
+		status            TEXT NOT NULL,
+		model             TEXT NOT NULL,
+		prompt_tokens     INTEGER NOT NULL,
+		completion_tokens INTEGER NOT NULL,
+		sent_at           BIGINT NOT NULL,
+		answered_at       BIGINT NOT NULL,
+		PRIMARY KEY (chat_jid, message_id)
+	)`,
+	`CREATE INDEX IF NOT EXISTS whatsapp_gpt_conversation_answered_at_idx ON whatsapp_gpt_conversation (answered_at)`,
+	`CREATE TABLE IF NOT EXISTS whatsapp_gpt_account (
+		account_jid   TEXT PRIMARY KEY,
+		tag           TEXT NOT NULL,
+		persona       TEXT NOT NULL,
+		allow         TEXT NOT NULL,
+		pii_redaction TEXT NOT NULL DEFAULT '',
+		pii_restore   TEXT NOT NULL DEFAULT '',
+		pii_detectors TEXT NOT NULL DEFAULT '',
+		updated_at    BIGINT NOT NULL
+	)`,
+	`CREATE TABLE IF NOT EXISTS whatsapp_gpt_daemon (
+		instance     TEXT PRIMARY KEY,
+		heartbeat_at BIGINT NOT NULL
+	)`,
+}
+
+// Columns Added to Tables Created by Previous Versions
+var columns = []struct {
+	Table      string
+	Column     string
+	Definition string
+}{
+	{Table: "whatsapp_gpt_processed_message", Column: "status", Definition: "TEXT NOT NULL DEFAULT 'done'"},
+	{Table: "whatsapp_gpt_processed_message", Column: "owner", Definition: "TEXT NOT NULL DEFAULT ''"},
+	{Table: "whatsapp_gpt_pending_question", Column: "account_jid", Definition: "TEXT NOT NULL DEFAULT ''"},
+	{Table: "whatsapp_gpt_conversation", Column: "account_jid", Definition: "TEXT NOT NULL DEFAULT ''"},
+	{Table: "whatsapp_gpt_account", Column: "pii_redaction", Definition: "TEXT NOT NULL DEFAULT ''"},
+	{Table: "whatsapp_gpt_account", Column: "pii_restore", Definition: "TEXT NOT NULL DEFAULT ''"},
+	{Table: "whatsapp_gpt_account", Column: "pii_detectors", Definition: "TEXT NOT NULL DEFAULT ''"},
+}
+
+func init() {
+	var err error
+
+	DBType, err = env.GetEnvString("WHATSAPP_DATASTORE_TYPE")
+	if err != nil {
+		log.Println(log.LogLevelFatal, "Error Parse Environment Variable for WhatsApp Client Datastore Type")
+	}
+
+	DBURI, err = env.GetEnvString("WHATSAPP_DATASTORE_URI")
+	if err != nil {
+		log.Println(log.LogLevelFatal, "Error Parse Environment Variable for WhatsApp Client Datastore URI")
+	}
+
+	DB, err = sql.Open(DBType, DBURI)
+	if err != nil {
+		log.WithError(err).Println(log.LogLevelFatal, "Error Open WhatsApp Client Datastore")
+	}
+
+	err = Migrate(context.Background())
+	if err != nil {
+		log.WithError(err).Println(log.LogLevelFatal, "Error Migrate WhatsApp GPT Datastore Schema")
+	}
+}
+
+func Migrate(ctx context.Context) error {
+	for _, schema := range schemas {
+		_, err := DB.ExecContext(ctx, schema)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, column := range columns {
+		// Selecting The Column Fails When The Column is not Exist Yet
+		_, err := DB.ExecContext(ctx, "SELECT "+column.Column+" FROM "+column.Table+" LIMIT 0")
+		if err == nil {
+			continue
+		}
+
+		_, err = DB.ExecContext(ctx, "ALTER TABLE "+column.Table+" ADD COLUMN "+column.Column+" "+column.Definition)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+func GetChatLanguage(ctx context.Context, chatJID string) (string, error) {
+	var language string
+
+	err := DB.QueryRowContext(ctx,
+		`SELECT language FROM whatsapp_gpt_chat_language WHERE chat_jid = $1`,
+		chatJID,
+	).Scan(&language)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return language, nil
+}
+
+func SetChatLanguage(ctx context.Context, chatJID string, language string) error {
+	_, err := DB.ExecContext(ctx,
+		`INSERT INTO whatsapp_gpt_chat_language (chat_jid, language) VALUES ($1, $2)
+		ON CONFLICT (chat_jid) DO UPDATE SET language = excluded.language`,
+		chatJID, language,
+	)
+
+	return err
+}
+
+func DeleteChatLanguage(ctx context.Context, chatJID string) error {
+	_, err := DB.ExecContext(ctx,
+		`DELETE FROM whatsapp_gpt_chat_language WHERE chat_jid = $1`,
+		chatJID,
+	)
+
+	return err
+}
@@ -0,0 +1,71 @@
+package datastore
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	ProcessedStatusPending string = "pending"
+	ProcessedStatusDone    string = "done"
+)
+
+// ClaimProcessedMessage records the message as pending for the owner and
+// reports whether it was claimed by this call, so each message is only
+// processed once even when it is delivered multiple times. A pending claim
+// of another owner, like a crashed process, can be claimed again.
+func ClaimProcessedMessage(ctx context.Context, chatJID string, messageID string, owner string) (bool, error) {
+	result, err := DB.ExecContext(ctx,
+		`INSERT INTO whatsapp_gpt_processed_message (chat_jid, message_id, status, owner, processed_at) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (chat_jid, message_id) DO UPDATE SET
+			owner = excluded.owner,
+			processed_at = excluded.processed_at
+		WHERE whatsapp_gpt_processed_message.status = $3 AND whatsapp_gpt_processed_message.owner <> excluded.owner`,
+		chatJID, messageID, ProcessedStatusPending, owner, time.Now().Unix(),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// CompleteProcessedMessage marks the claimed message as done, so it will
+// never be processed again.
+func CompleteProcessedMessage(ctx context.Context, chatJID string, messageID string) error {
+	_, err := DB.ExecContext(ctx,
+		`UPDATE whatsapp_gpt_processed_message SET status = $1, processed_at = $2
+		WHERE chat_jid = $3 AND message_id = $4`,
+		ProcessedStatusDone, time.Now().Unix(), chatJID, messageID,
+	)
+
+	return err
+}
+
+// ReleaseProcessedMessage removes the pending claim of the message, so the
+// message can be processed again when it is delivered again.
+func ReleaseProcessedMessage(ctx context.Context, chatJID string, messageID string) error {
+	_, err := DB.ExecContext(ctx,
+		`DELETE FROM whatsapp_gpt_processed_message WHERE chat_jid = $1 AND message_id = $2 AND status = $3`,
+		chatJID, messageID, ProcessedStatusPending,
+	)
+
+	return err
+}
+
+func PurgeProcessedMessage(ctx context.Context, before time.Time) (int64, error) {
+	result, err := DB.ExecContext(ctx,
+		`DELETE FROM whatsapp_gpt_processed_message WHERE processed_at < $1`,
+		before.Unix(),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
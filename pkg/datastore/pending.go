@@ -0,0 +1,94 @@
+package datastore
+
+import (
+	"context"
+	"time"
+)
+
+// PendingQuestion is a question which is accepted but not answered yet when
+// the daemon is shutting down, so it can be answered after restarting.
+type PendingQuestion struct {
+	AccountJID    string
+	ChatJID       string
+	MessageID     string
+	SenderJID     string
+	Question      string
+	IsLowPriority bool
+	SentAt        time.Time
+	CreatedAt     time.Time
+}
+
+func SavePendingQuestion(ctx context.Context, pending PendingQuestion) error {
+	isLowPriority := 0
+	if pending.IsLowPriority {
+		isLowPriority = 1
+	}
+
+	_, err := DB.ExecContext(ctx,
+		`INSERT INTO whatsapp_gpt_pending_question (chat_jid, message_id, sender_jid, question, is_low_priority, sent_at, created_at, account_jid)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (chat_jid, message_id) DO UPDATE SET
+			account_jid = excluded.account_jid,
+			sender_jid = excluded.sender_jid,
+			question = excluded.question,
+			is_low_priority = excluded.is_low_priority,
+			sent_at = excluded.sent_at,
+			created_at = excluded.created_at`,
+		pending.ChatJID, pending.MessageID, pending.SenderJID, pending.Question,
+		isLowPriority, pending.SentAt.Unix(), pending.CreatedAt.Unix(), pending.AccountJID,
+	)
+
+	return err
+}
+
+// TakePendingQuestion returns the pending questions of the account ordered
+// by their sent time and removes them from the datastore. Questions saved
+// without account are taken by the first account asking for them.
+func TakePendingQuestion(ctx context.Context, accountJID string) ([]PendingQuestion, error) {
+	tx, err := DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT account_jid, chat_jid, message_id, sender_jid, question, is_low_priority, sent_at, created_at
+		FROM whatsapp_gpt_pending_question WHERE account_jid = $1 OR account_jid = '' ORDER BY sent_at`,
+		accountJID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var pendings []PendingQuestion
+
+	for rows.Next() {
+		var pending PendingQuestion
+		var sentAt, createdAt int64
+		var isLowPriority int
+
+		err = rows.Scan(&pending.AccountJID, &pending.ChatJID, &pending.MessageID, &pending.SenderJID, &pending.Question, &isLowPriority, &sentAt, &createdAt)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		pending.IsLowPriority = isLowPriority != 0
+		pending.SentAt = time.Unix(sentAt, 0)
+		pending.CreatedAt = time.Unix(createdAt, 0)
+
+		pendings = append(pendings, pending)
+	}
+
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `DELETE FROM whatsapp_gpt_pending_question WHERE account_jid = $1 OR account_jid = ''`, accountJID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pendings, tx.Commit()
+}
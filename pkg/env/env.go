@@ -5,8 +5,6 @@ import (
 	"os"
 	"strconv"
 	"strings"
-
-	_ "github.com/joho/godotenv/autoload"
 )
 
 func SanitizeEnv(envName string) (string, error) {
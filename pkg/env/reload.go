@@ -0,0 +1,86 @@
+package env
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/joho/godotenv"
+)
+
+// Change is a setting which value is changed by reloading.
+type Change struct {
+	Name string
+	Old  string
+	New  string
+}
+
+var (
+	fileKeys     = make(map[string]bool)
+	externalKeys = make(map[string]bool)
+	fileMutex    sync.Mutex
+)
+
+func init() {
+	// Variables Set Before Starting Take Precedence Over The .env File
+	for _, variable := range os.Environ() {
+		name, _, _ := strings.Cut(variable, "=")
+		externalKeys[name] = true
+	}
+
+	_ = Reload()
+}
+
+// Reload reads the .env file again and applies its values to the variables
+// not set by the environment itself, so values from the container or the
+// shell are kept. Variables removed from the .env file are unset. A missing
+// .env file is not an error since the variables can be set by the
+// environment itself.
+func Reload() error {
+	fileMutex.Lock()
+	defer fileMutex.Unlock()
+
+	values, err := godotenv.Read()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	for name := range fileKeys {
+		if _, isExist := values[name]; !isExist {
+			os.Unsetenv(name)
+			delete(fileKeys, name)
+		}
+	}
+
+	for name, value := range values {
+		if externalKeys[name] {
+			continue
+		}
+
+		os.Setenv(name, value)
+		fileKeys[name] = true
+	}
+
+	return nil
+}
+
+// Diff returns the changed settings between two setting maps ordered
+// by the setting name.
+func Diff(old map[string]string, new map[string]string) []Change {
+	var changes []Change
+
+	for name, newValue := range new {
+		if oldValue := old[name]; oldValue != newValue {
+			changes = append(changes, Change{Name: name, Old: oldValue, New: newValue})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+
+	return changes
+}
@@ -0,0 +1,380 @@
+package filter
+
+import (
+	"bufio"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/env"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/log"
+)
+
+const (
+	ModeExact     string = "exact"
+	ModeSubstring string = "substring"
+	ModeRegex     string = "regex"
+)
+
+const (
+	AnswerActionOff        string = "off"
+	AnswerActionMask       string = "mask"
+	AnswerActionRegenerate string = "regenerate"
+	AnswerActionRefuse     string = "refuse"
+)
+
+const directiveInherit string = "%inherit"
+
+const listBlockedWord string = "" +
+	"lgbt|lesbian|gay|homosexual|homoseksual|bisexual|biseksual|transgender|" +
+	"fuck|sex|ngentot|entot|ngewe|ewe|masturbate|masturbasi|coli|colmek|jilmek|" +
+	"cock|penis|kontol|vagina|memek|porn|porno|bokep"
+
+type Rule struct {
+	Term   string
+	Mode   string
+	Source string
+	regex  *regexp.Regexp
+}
+
+type Match struct {
+	Rule
+	Text string
+}
+
+type ruleSet struct {
+	global  []Rule
+	groups  map[string][]Rule
+	modTime map[string]time.Time
+}
+
+var rules atomic.Pointer[ruleSet]
+
+var reloadMutex sync.Mutex
+
+var (
+	BlockedWord,
+	BlockedWordMode,
+	BlockedWordGroupDir,
+	BlockedWordAnswerAction string
+	BlockedWordFiles   []string
+	BlockedWordDefault bool
+	BlockedWordReload  int
+)
+
+func init() {
+	var err error
+
+	BlockedWord = strings.TrimSpace(os.Getenv("WHATSAPP_GPT_BLOCKED_WORD"))
+
+	BlockedWordMode, err = env.GetEnvString("WHATSAPP_GPT_BLOCKED_WORD_MODE")
+	if err != nil {
+		BlockedWordMode = ModeExact
+	}
+
+	BlockedWordMode = strings.ToLower(BlockedWordMode)
+	if !isValidMode(BlockedWordMode) {
+		log.Println(log.LogLevelWarn, "Unknown Blocked Word Mode '"+BlockedWordMode+"', Fallback to '"+ModeExact+"'")
+		BlockedWordMode = ModeExact
+	}
+
+	blockedWordFiles, err := env.GetEnvString("WHATSAPP_GPT_BLOCKED_WORD_FILE")
+	if err == nil {
+		for _, file := range strings.Split(blockedWordFiles, ",") {
+			if file = strings.TrimSpace(file); len(file) > 0 {
+				BlockedWordFiles = append(BlockedWordFiles, file)
+			}
+		}
+	}
+
+	BlockedWordGroupDir, _ = env.GetEnvString("WHATSAPP_GPT_BLOCKED_WORD_GROUP_DIR")
+
+	// Built-in List is Only Used by Default When No Other List is Configured
+	BlockedWordDefault, err = env.GetEnvBool("WHATSAPP_GPT_BLOCKED_WORD_DEFAULT")
+	if err != nil {
+		BlockedWordDefault = len(BlockedWord) == 0 && len(BlockedWordFiles) == 0 && len(BlockedWordGroupDir) == 0
+	}
+
+	BlockedWordAnswerAction, err = env.GetEnvString("WHATSAPP_GPT_BLOCKED_WORD_ANSWER_ACTION")
+	if err != nil {
+		BlockedWordAnswerAction = AnswerActionOff
+	}
+
+	BlockedWordAnswerAction = strings.ToLower(BlockedWordAnswerAction)
+	switch BlockedWordAnswerAction {
+	case AnswerActionOff, AnswerActionMask, AnswerActionRegenerate, AnswerActionRefuse:
+	default:
+		log.Println(log.LogLevelWarn, "Unknown Blocked Word Answer Action '"+BlockedWordAnswerAction+"', Fallback to '"+AnswerActionOff+"'")
+		BlockedWordAnswerAction = AnswerActionOff
+	}
+
+	BlockedWordReload, err = env.GetEnvInt("WHATSAPP_GPT_BLOCKED_WORD_RELOAD_INTERVAL")
+	if err != nil {
+		BlockedWordReload = 30
+	}
+
+	err = Reload()
+	if err != nil {
+		log.WithError(err).Println(log.LogLevelFatal, "Error Load Blocked Word List")
+	}
+}
+
+func isValidMode(mode string) bool {
+	switch mode {
+	case ModeExact, ModeSubstring, ModeRegex:
+		return true
+	default:
+		return false
+	}
+}
+
+// wordBoundary returns a word boundary for the term edge. A boundary never
+// matches next to a non-word character such as in "c++" or ".net", so it is
+// only added to edges which are word characters.
+func wordBoundary(edge string) string {
+	if len(edge) == 1 && (edge[0] == '_' ||
+		(edge[0] >= '0' && edge[0] <= '9') || (edge[0] >= 'a' && edge[0] <= 'z') || (edge[0] >= 'A' && edge[0] <= 'Z')) {
+		return "\\b"
+	}
+
+	return ""
+}
+
+func NewRule(term string, mode string, source string) (Rule, error) {
+	var pattern string
+
+	if len(term) == 0 {
+		return Rule{}, errors.New("Blocked Word is Empty")
+	}
+
+	switch mode {
+	case ModeExact:
+		pattern = "(?i)" + wordBoundary(term[:1]) + regexp.QuoteMeta(term) + wordBoundary(term[len(term)-1:])
+	case ModeSubstring:
+		pattern = "(?i)" + regexp.QuoteMeta(term)
+	case ModeRegex:
+		pattern = "(?i)" + term
+	default:
+		return Rule{}, errors.New("Unknown Blocked Word Mode '" + mode + "'")
+	}
+
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return Rule{}, err
+	}
+
+	return Rule{
+		Term:   term,
+		Mode:   mode,
+		Source: source,
+		regex:  regex,
+	}, nil
+}
+
+// parseRules reads one term per line. Empty lines and lines starting with '#'
+// are ignored, and a line can override the default mode with a prefix such as
+// "regex:", "exact:" or "substring:".
+func parseRules(path string) ([]Rule, bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+	defer file.Close()
+
+	var parsed []Rule
+	isInherit := false
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if line == directiveInherit {
+			isInherit = true
+			continue
+		}
+
+		mode, term := BlockedWordMode, line
+		if prefix, rest, isFound := strings.Cut(line, ":"); isFound && isValidMode(strings.ToLower(prefix)) {
+			mode, term = strings.ToLower(prefix), strings.TrimSpace(rest)
+		}
+
+		rule, err := NewRule(term, mode, path)
+		if err != nil {
+			log.WithError(err).WithFields(log.Fields{"term": term, "file": path}).Println(log.LogLevelWarn, "Skipping Invalid Blocked Word")
+			continue
+		}
+
+		parsed = append(parsed, rule)
+	}
+
+	return parsed, isInherit, scanner.Err()
+}
+
+func Reload() error {
+	reloadMutex.Lock()
+	defer reloadMutex.Unlock()
+
+	loaded := &ruleSet{
+		groups:  make(map[string][]Rule),
+		modTime: make(map[string]time.Time),
+	}
+
+	if BlockedWordDefault {
+		rule, _ := NewRule("\\b("+listBlockedWord+")", ModeRegex, "default")
+		loaded.global = append(loaded.global, rule)
+	}
+
+	if len(BlockedWord) > 0 {
+		rule, err := NewRule("\\b("+BlockedWord+")", ModeRegex, "environment")
+		if err != nil {
+			return err
+		}
+
+		loaded.global = append(loaded.global, rule)
+	}
+
+	for _, path := range BlockedWordFiles {
+		parsed, _, err := parseRules(path)
+		if err != nil {
+			return err
+		}
+
+		loaded.global = append(loaded.global, parsed...)
+		loaded.modTime[path] = fileModTime(path)
+	}
+
+	if len(BlockedWordGroupDir) > 0 {
+		paths, err := filepath.Glob(filepath.Join(BlockedWordGroupDir, "*.txt"))
+		if err != nil {
+			return err
+		}
+
+		for _, path := range paths {
+			parsed, isInherit, err := parseRules(path)
+			if err != nil {
+				return err
+			}
+
+			if isInherit {
+				parsed = append(append([]Rule{}, loaded.global...), parsed...)
+			}
+
+			loaded.groups[strings.TrimSuffix(filepath.Base(path), ".txt")] = parsed
+			loaded.modTime[path] = fileModTime(path)
+		}
+
+		loaded.modTime[BlockedWordGroupDir] = fileModTime(BlockedWordGroupDir)
+	}
+
+	rules.Store(loaded)
+	return nil
+}
+
+func fileModTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+
+	return info.ModTime()
+}
+
+func isChanged() bool {
+	current := rules.Load()
+	if current == nil {
+		return true
+	}
+
+	for path, modTime := range current.modTime {
+		if !fileModTime(path).Equal(modTime) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Watch reloads the blocked word lists whenever one of the files is changed
+// until the stop channel is closed.
+func Watch(stop <-chan struct{}) {
+	if BlockedWordReload <= 0 || (len(BlockedWordFiles) == 0 && len(BlockedWordGroupDir) == 0) {
+		return
+	}
+
+	ticker := time.NewTicker(time.Duration(BlockedWordReload) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			if isChanged() {
+				err := Reload()
+				if err != nil {
+					log.WithError(err).Println(log.LogLevelError, "Failed to Reload Blocked Word List")
+					continue
+				}
+
+				log.Println(log.LogLevelInfo, "Blocked Word List Reloaded")
+			}
+		}
+	}
+}
+
+func chatRules(chatJID string) []Rule {
+	current := rules.Load()
+	if current == nil {
+		return nil
+	}
+
+	if groupRules, isExist := current.groups[chatJID]; isExist {
+		return groupRules
+	}
+
+	return current.global
+}
+
+func Check(chatJID string, text string) (Match, bool) {
+	for _, rule := range chatRules(chatJID) {
+		if matched := rule.regex.FindString(text); len(matched) > 0 {
+			return Match{Rule: rule, Text: matched}, true
+		}
+	}
+
+	return Match{}, false
+}
+
+func CheckAll(chatJID string, text string) []Match {
+	var matches []Match
+
+	for _, rule := range chatRules(chatJID) {
+		for _, matched := range rule.regex.FindAllString(text, -1) {
+			matches = append(matches, Match{Rule: rule, Text: matched})
+		}
+	}
+
+	return matches
+}
+
+func Mask(chatJID string, text string) (string, []Match) {
+	var matches []Match
+
+	for _, rule := range chatRules(chatJID) {
+		text = rule.regex.ReplaceAllStringFunc(text, func(matched string) string {
+			matches = append(matches, Match{Rule: rule, Text: matched})
+			return strings.Repeat("*", utf8.RuneCountInString(matched))
+		})
+	}
+
+	return text, matches
+}
@@ -0,0 +1,218 @@
+package gpt
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/env"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/pii"
+)
+
+// GPTModelConfig is the model configuration which can be reloaded
+// while the daemon is running.
+type GPTModelConfig struct {
+	Name,
+	Prompt,
+	ReasoningMode,
+	ReasoningPrefix string
+	Token int
+	Temperature,
+	TopP,
+	PenaltyPresence,
+	PenaltyFreq float32
+}
+
+var modelConfig atomic.Pointer[GPTModelConfig]
+
+// GPTModel returns the current model configuration. Callers should keep the
+// returned configuration for a whole request so a reload will not mix values.
+func GPTModel() *GPTModelConfig {
+	return modelConfig.Load()
+}
+
+func GPTSetModel(config *GPTModelConfig) {
+	modelConfig.Store(config)
+}
+
+type personaKey struct{}
+
+// GPTWithPersona returns a context which answers are using the persona as
+// system prompt instead of the configured system prompt.
+func GPTWithPersona(ctx context.Context, persona string) context.Context {
+	return context.WithValue(ctx, personaKey{}, persona)
+}
+
+func gptPrompt(ctx context.Context) string {
+	if persona, isExist := ctx.Value(personaKey{}).(string); isExist && len(strings.TrimSpace(persona)) != 0 {
+		return persona
+	}
+
+	return GPTModel().Prompt
+}
+
+type piiKey struct{}
+
+// GPTWithPII returns a context which text sent to the model and the
+// moderation endpoint is redacted using the PII configuration instead of
+// the global PII configuration.
+func GPTWithPII(ctx context.Context, config pii.Config) context.Context {
+	return context.WithValue(ctx, piiKey{}, config)
+}
+
+func gptPII(ctx context.Context) pii.Config {
+	if config, isExist := ctx.Value(piiKey{}).(pii.Config); isExist {
+		return config
+	}
+
+	return GPTModelPII
+}
+
+// isInvalidEnv reports whether the variable is set but its value can not be
+// parsed, as opposed to an empty variable which uses the default value.
+func isInvalidEnv(envName string, err error) bool {
+	_, errEmpty := env.SanitizeEnv(envName)
+	return err != nil && errEmpty == nil
+}
+
+// gptLoadPII reads the PII redaction configuration from the environment.
+// Unknown detectors are rejected so a typo will not disable a detector.
+func gptLoadPII() (pii.Config, error) {
+	var err error
+
+	config := pii.Config{}
+
+	config.Enabled, err = env.GetEnvBool("GPT_MODEL_PII_REDACTION")
+	if err != nil {
+		config.Enabled = false
+	}
+
+	config.Restore, err = env.GetEnvBool("GPT_MODEL_PII_RESTORE")
+	if err != nil {
+		config.Restore = true
+	}
+
+	detectors, err := env.GetEnvString("GPT_MODEL_PII_DETECTORS")
+	if err != nil {
+		config.Detectors = pii.Detectors
+		return config, nil
+	}
+
+	for _, detector := range strings.Split(detectors, ",") {
+		detector = strings.ToLower(strings.TrimSpace(detector))
+		if !pii.IsDetector(detector) {
+			config.Detectors = pii.Detectors
+			return config, errors.New("Environment Variable 'GPT_MODEL_PII_DETECTORS' Has Unknown Detector '" + detector + "', Should be One of " + strings.Join(pii.Detectors, ", "))
+		}
+
+		config.Detectors = append(config.Detectors, detector)
+	}
+
+	return config, nil
+}
+
+// GPTLoadModel reads the model configuration from the environment. Invalid
+// values are replaced by their default and reported in the returned error.
+func GPTLoadModel() (*GPTModelConfig, error) {
+	var errs []error
+	var err error
+
+	config := &GPTModelConfig{}
+
+	invalid := func(envName string, reason string) {
+		errs = append(errs, errors.New("Environment Variable '"+envName+"' "+reason))
+	}
+
+	config.Name, err = env.GetEnvString("GPT_MODEL_NAME")
+	if err != nil {
+		config.Name = "gpt-3.5-turbo"
+	}
+
+	config.Prompt, err = env.GetEnvString("GPT_MODEL_SYSTEM_PROMPT")
+	if err != nil {
+		config.Prompt = ""
+	}
+
+	config.Token, err = env.GetEnvInt("GPT_MODEL_TOKEN")
+	if err != nil || config.Token <= 0 {
+		if err == nil || isInvalidEnv("GPT_MODEL_TOKEN", err) {
+			invalid("GPT_MODEL_TOKEN", "Should be a Positive Number")
+		}
+
+		config.Token = 4096
+	}
+
+	config.Temperature, err = env.GetEnvFloat32("GPT_MODEL_TEMPERATURE")
+	if err != nil || config.Temperature < 0 || config.Temperature > 2 {
+		if err == nil || isInvalidEnv("GPT_MODEL_TEMPERATURE", err) {
+			invalid("GPT_MODEL_TEMPERATURE", "Should be a Number Between 0 and 2")
+		}
+
+		config.Temperature = 0.8
+	}
+
+	config.TopP, err = env.GetEnvFloat32("GPT_MODEL_TOP_P")
+	if err != nil || config.TopP < 0 || config.TopP > 1 {
+		if err == nil || isInvalidEnv("GPT_MODEL_TOP_P", err) {
+			invalid("GPT_MODEL_TOP_P", "Should be a Number Between 0 and 1")
+		}
+
+		config.TopP = 0.9
+	}
+
+	config.PenaltyPresence, err = env.GetEnvFloat32("GPT_MODEL_PENALTY_PRESENCE")
+	if err != nil || config.PenaltyPresence < -2 || config.PenaltyPresence > 2 {
+		if err == nil || isInvalidEnv("GPT_MODEL_PENALTY_PRESENCE", err) {
+			invalid("GPT_MODEL_PENALTY_PRESENCE", "Should be a Number Between -2 and 2")
+		}
+
+		config.PenaltyPresence = 0
+	}
+
+	config.PenaltyFreq, err = env.GetEnvFloat32("GPT_MODEL_PENALTY_FREQUENCY")
+	if err != nil || config.PenaltyFreq < -2 || config.PenaltyFreq > 2 {
+		if err == nil || isInvalidEnv("GPT_MODEL_PENALTY_FREQUENCY", err) {
+			invalid("GPT_MODEL_PENALTY_FREQUENCY", "Should be a Number Between -2 and 2")
+		}
+
+		config.PenaltyFreq = 0
+	}
+
+	config.ReasoningMode, err = env.GetEnvString("GPT_MODEL_REASONING_MODE")
+	if err != nil {
+		config.ReasoningMode = ReasoningModeStrip
+	}
+
+	config.ReasoningMode = strings.ToLower(config.ReasoningMode)
+	switch config.ReasoningMode {
+	case ReasoningModeStrip, ReasoningModeLog, ReasoningModeSend:
+	default:
+		invalid("GPT_MODEL_REASONING_MODE", "Has Unknown Mode '"+config.ReasoningMode+"'")
+		config.ReasoningMode = ReasoningModeStrip
+	}
+
+	config.ReasoningPrefix, err = env.GetEnvString("GPT_MODEL_REASONING_PREFIX")
+	if err != nil {
+		config.ReasoningPrefix = ""
+	}
+
+	return config, errors.Join(errs...)
+}
+
+// Settings returns the configuration values keyed by their environment
+// variable name, used to log what is changed by reloading.
+func (c *GPTModelConfig) Settings() map[string]string {
+	return map[string]string{
+		"GPT_MODEL_NAME":              c.Name,
+		"GPT_MODEL_SYSTEM_PROMPT":     c.Prompt,
+		"GPT_MODEL_TOKEN":             strconv.Itoa(c.Token),
+		"GPT_MODEL_TEMPERATURE":       strconv.FormatFloat(float64(c.Temperature), 'g', -1, 32),
+		"GPT_MODEL_TOP_P":             strconv.FormatFloat(float64(c.TopP), 'g', -1, 32),
+		"GPT_MODEL_PENALTY_PRESENCE":  strconv.FormatFloat(float64(c.PenaltyPresence), 'g', -1, 32),
+		"GPT_MODEL_PENALTY_FREQUENCY": strconv.FormatFloat(float64(c.PenaltyFreq), 'g', -1, 32),
+		"GPT_MODEL_REASONING_MODE":    c.ReasoningMode,
+		"GPT_MODEL_REASONING_PREFIX":  c.ReasoningPrefix,
+	}
+}
@@ -4,57 +4,42 @@ import (
 	"context"
 	"errors"
 	"io"
-	"os"
+	"net/http"
 	"regexp"
 	"strings"
+	"time"
 
 	OpenAI "github.com/sashabaranov/go-openai"
 
 	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/env"
 	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/log"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/metrics"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/pii"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/tracing"
 )
 
 var OAIClient *OpenAI.Client
 
-var (
-	WAGPTBlockedWord      string
-	WAGPTBlockedWordRegex *regexp.Regexp
-)
-
 var (
 	OAIHost,
 	OAIHostPath,
 	OAIAPIKey string
+	OAIStreamUsage bool
 )
 
-var (
-	GPTModelName,
-	GPTModelPrompt string
-	GPTModelToken int
-	GPTModelTemperature,
-	GPTModelTopP,
-	GPTModelPenaltyPresence,
-	GPTModelPenaltyFreq float32
+var GPTModelPII pii.Config
+
+const (
+	ReasoningModeStrip string = "strip"
+	ReasoningModeLog   string = "log"
+	ReasoningModeSend  string = "send"
 )
 
-const listBlockedWord string = "" +
-	"lgbt|lesbian|gay|homosexual|homoseksual|bisexual|biseksual|transgender|" +
-	"fuck|sex|ngentot|entot|ngewe|ewe|masturbate|masturbasi|coli|colmek|jilmek|" +
-	"cock|penis|kontol|vagina|memek|porn|porno|bokep"
+var thinkingResponseRegex = regexp.MustCompile("^([\\s\\S]*)<\\/think>\\n?")
 
 func init() {
 	var err error
 
-	// -----------------------------------------------------------------------
-	// WhatsApp GPT Configuration Environment
-	// -----------------------------------------------------------------------
-	WAGPTBlockedWord = strings.TrimSpace(os.Getenv("WHATSAPP_GPT_BLOCKED_WORD"))
-	if len(WAGPTBlockedWord) > 0 {
-		WAGPTBlockedWordRegex = regexp.MustCompile("\\b(?i)(" + listBlockedWord + "|" + WAGPTBlockedWord + ")")
-	} else {
-		WAGPTBlockedWordRegex = regexp.MustCompile("\\b(?i)(" + listBlockedWord + ")")
-	}
-
 	// -----------------------------------------------------------------------
 	// OpenAI Configuration Environment
 	// -----------------------------------------------------------------------
@@ -73,102 +58,220 @@ func init() {
 		log.Println(log.LogLevelFatal, "Error Parse Environment Variable for OpenAI API Key")
 	}
 
+	// Not Every OpenAI Compatible Endpoint Accepts Stream Options
+	OAIStreamUsage, err = env.GetEnvBool("OPENAI_STREAM_USAGE")
+	if err != nil {
+		OAIStreamUsage = false
+	}
+
 	// -----------------------------------------------------------------------
 	// GPT Configuration Environment
 	// -----------------------------------------------------------------------
-	GPTModelName, err = env.GetEnvString("GPT_MODEL_NAME")
+	config, err := GPTLoadModel()
 	if err != nil {
-		GPTModelName = "gpt-3.5-turbo"
+		log.WithError(err).Println(log.LogLevelWarn, "Invalid GPT Model Configuration, Fallback to Default Values")
 	}
 
-	GPTModelPrompt, err = env.GetEnvString("GPT_MODEL_SYSTEM_PROMPT")
+	GPTSetModel(config)
+
+	GPTModelPII, err = gptLoadPII()
 	if err != nil {
-		GPTModelPrompt = ""
+		log.WithError(err).Println(log.LogLevelWarn, "Invalid GPT PII Configuration, Fallback to Default Values")
 	}
 
-	GPTModelToken, err = env.GetEnvInt("GPT_MODEL_TOKEN")
-	if err != nil {
-		GPTModelToken = 4096
+	// -----------------------------------------------------------------------
+	// GPT Engine Initialization
+	// -----------------------------------------------------------------------
+	OAIConfig := OpenAI.DefaultConfig(OAIAPIKey)
+	OAIConfig.BaseURL = OAIHost + OAIHostPath
+	OAIConfig.HTTPClient = &http.Client{
+		Transport: &retryAfterTransport{base: http.DefaultTransport},
 	}
 
-	GPTModelTemperature, err = env.GetEnvFloat32("GPT_MODEL_TEMPERATURE")
-	if err != nil {
-		GPTModelTemperature = 0.8
+	OAIClient = OpenAI.NewClientWithConfig(OAIConfig)
+}
+
+func GPTResponse(ctx context.Context, question string, instructions ...string) (response string, reasoning string, err error) {
+	var OAIGPTChatCompletion []OpenAI.ChatCompletionMessage
+
+	PIIConfig := gptPII(ctx)
+
+	// Redact Personal Data Before Sending Question to The Model
+	var PIIVault map[string]string
+	if PIIConfig.Enabled {
+		question, PIIVault = pii.Redact(question, PIIConfig.Detectors)
+		if len(PIIVault) > 0 {
+			log.WithFields(log.Fields{"count": len(PIIVault)}).Println(log.LogLevelInfo, "Redacted Personal Data from Question")
+			instructions = append(instructions, "Some personal data in the user message has been replaced with placeholders "+
+				"such as [EMAIL_1] or [PHONE_1]. Keep these placeholders exactly as they are when you need to refer to them.")
+		}
 	}
 
-	GPTModelTopP, err = env.GetEnvFloat32("GPT_MODEL_TOP_P")
-	if err != nil {
-		GPTModelTopP = 0.9
+	if prompt := gptPrompt(ctx); len(strings.TrimSpace(prompt)) != 0 {
+		OAIGPTChatCompletion = append(OAIGPTChatCompletion, OpenAI.ChatCompletionMessage{
+			Role:    OpenAI.ChatMessageRoleSystem,
+			Content: prompt,
+		})
 	}
 
-	GPTModelPenaltyPresence, err = env.GetEnvFloat32("GPT_MODEL_PENALTY_PRESENCE")
-	if err != nil {
-		GPTModelPenaltyPresence = 0
+	// Additional Instructions Are Placed After The Persona Prompt
+	// So They Take Precedence Over It
+	for _, instruction := range instructions {
+		if len(strings.TrimSpace(instruction)) != 0 {
+			OAIGPTChatCompletion = append(OAIGPTChatCompletion, OpenAI.ChatCompletionMessage{
+				Role:    OpenAI.ChatMessageRoleSystem,
+				Content: instruction,
+			})
+		}
 	}
 
-	GPTModelPenaltyFreq, err = env.GetEnvFloat32("GPT_MODEL_PENALTY_FREQUENCY")
+	OAIGPTChatCompletion = append(OAIGPTChatCompletion, OpenAI.ChatCompletionMessage{
+		Role:    OpenAI.ChatMessageRoleUser,
+		Content: question,
+	})
+
+	response, reasoning, err = gptCompletion(ctx, OAIGPTChatCompletion)
 	if err != nil {
-		GPTModelPenaltyFreq = 0
+		return "", "", err
 	}
 
-	// -----------------------------------------------------------------------
-	// GPT Engine Initialization
-	// -----------------------------------------------------------------------
-	OAIConfig := OpenAI.DefaultConfig(OAIAPIKey)
-	OAIConfig.BaseURL = OAIHost + OAIHostPath
+	if PIIConfig.Restore && len(PIIVault) > 0 {
+		response = pii.Restore(response, PIIVault)
+		reasoning = pii.Restore(reasoning, PIIVault)
+	}
 
-	OAIClient = OpenAI.NewClientWithConfig(OAIConfig)
+	return response, reasoning, nil
+}
+
+// GPTTranslateInstruction returns the instruction to translate the user
+// text into the language.
+func GPTTranslateInstruction(language string) string {
+	return "Translate the text given by the user into " + language + ". " +
+		"Reply only with the translated text without any explanation, notes or quotation marks."
 }
 
-func GPTResponse(question string) (response string, err error) {
-	if bool(WAGPTBlockedWordRegex.MatchString(question)) {
-		return "Sorry, the AI can not response due to it is containing some blocked word 🥺", nil
+func GPTTranslate(ctx context.Context, text string, language string) (string, error) {
+	// Redact Personal Data Before Sending Text to The Model
+	var PIIVault map[string]string
+	if PIIConfig := gptPII(ctx); PIIConfig.Enabled {
+		text, PIIVault = pii.Redact(text, PIIConfig.Detectors)
+	}
+
+	OAIGPTChatCompletion := []OpenAI.ChatCompletionMessage{
+		{
+			Role:    OpenAI.ChatMessageRoleSystem,
+			Content: "You are a translation engine. " + GPTTranslateInstruction(language),
+		},
+		{
+			Role:    OpenAI.ChatMessageRoleUser,
+			Content: text,
+		},
 	}
 
+	response, _, err := gptCompletion(ctx, OAIGPTChatCompletion)
+	if err != nil {
+		return "", err
+	}
+
+	// Translation Should Always Keep The Original Personal Data
+	return pii.Restore(response, PIIVault), nil
+}
+
+func gptCompletion(ctx context.Context, OAIGPTChatCompletion []OpenAI.ChatCompletionMessage) (string, string, error) {
 	isStream := new(bool)
 	*isStream = true
 
-	var OAIGPTResponseText string
-	var OAIGPTChatCompletion []OpenAI.ChatCompletionMessage
+	var OAIGPTResponseText, OAIGPTReasoningText string
 
-	if len(strings.TrimSpace(GPTModelPrompt)) != 0 {
-		OAIGPTChatCompletion = []OpenAI.ChatCompletionMessage{
-			{
-				Role:    OpenAI.ChatMessageRoleSystem,
-				Content: GPTModelPrompt,
-			},
-			{
-				Role:    OpenAI.ChatMessageRoleUser,
-				Content: question,
-			},
-		}
-	} else {
-		OAIGPTChatCompletion = []OpenAI.ChatCompletionMessage{
-			{
-				Role:    OpenAI.ChatMessageRoleUser,
-				Content: question,
-			},
-		}
-	}
+	config := GPTModel()
 
 	OAIGPTPrompt := OpenAI.ChatCompletionRequest{
-		Model:            GPTModelName,
-		MaxTokens:        GPTModelToken,
-		Temperature:      GPTModelTemperature,
-		TopP:             GPTModelTopP,
-		PresencePenalty:  GPTModelPenaltyPresence,
-		FrequencyPenalty: GPTModelPenaltyFreq,
+		Model:            config.Name,
+		MaxTokens:        config.Token,
+		Temperature:      config.Temperature,
+		TopP:             config.TopP,
+		PresencePenalty:  config.PenaltyPresence,
+		FrequencyPenalty: config.PenaltyFreq,
 		Messages:         OAIGPTChatCompletion,
 		Stream:           *isStream,
 	}
 
+	// Request Token Usage in The Last Chunk When Enabled
+	if OAIStreamUsage {
+		OAIGPTPrompt.StreamOptions = &OpenAI.StreamOptions{
+			IncludeUsage: true,
+		}
+	}
+
+	// Retry Transient Failures Like Rate Limits and Server Errors
+	err := gptRetry(ctx, func(ctx context.Context) error {
+		var err error
+
+		OAIGPTResponseText, OAIGPTReasoningText, err = gptStream(ctx, OAIGPTPrompt)
+		return err
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	// Separate Inline Thinking Tags from The Response
+	CleanThinkingResponse := OAIGPTResponseText
+	if ThinkingMatch := thinkingResponseRegex.FindStringSubmatch(OAIGPTResponseText); ThinkingMatch != nil {
+		InlineReasoningText := strings.TrimSpace(ThinkingMatch[1])
+		InlineReasoningText = strings.TrimSpace(strings.TrimPrefix(InlineReasoningText, "<think>"))
+
+		if len(InlineReasoningText) > 0 {
+			OAIGPTReasoningText = strings.TrimSpace(OAIGPTReasoningText + "\n" + InlineReasoningText)
+		}
+
+		CleanThinkingResponse = OAIGPTResponseText[len(ThinkingMatch[0]):]
+	}
+
+	OAIGPTReasoningText = strings.TrimSpace(OAIGPTReasoningText)
+
+	switch config.ReasoningMode {
+	case ReasoningModeLog:
+		if len(OAIGPTReasoningText) > 0 {
+			log.WithFields(log.Fields{log.FieldModel: config.Name, "reasoning": OAIGPTReasoningText}).Println(log.LogLevelInfo, "OpenAI GPT Reasoning")
+		}
+
+		OAIGPTReasoningText = ""
+	case ReasoningModeSend:
+	default:
+		OAIGPTReasoningText = ""
+	}
+
+	OAIGPTResponseBuffer := strings.TrimSpace(CleanThinkingResponse)
+	OAIGPTResponseBuffer = strings.TrimLeft(OAIGPTResponseBuffer, "?\n")
+	OAIGPTResponseBuffer = strings.TrimLeft(OAIGPTResponseBuffer, "!\n")
+	OAIGPTResponseBuffer = strings.TrimLeft(OAIGPTResponseBuffer, ":\n")
+	OAIGPTResponseBuffer = strings.TrimLeft(OAIGPTResponseBuffer, "'\n")
+	OAIGPTResponseBuffer = strings.TrimLeft(OAIGPTResponseBuffer, ".\n")
+	OAIGPTResponseBuffer = strings.TrimLeft(OAIGPTResponseBuffer, "\n")
+
+	return OAIGPTResponseBuffer, OAIGPTReasoningText, nil
+}
+
+func gptStream(ctx context.Context, OAIGPTPrompt OpenAI.ChatCompletionRequest) (string, string, error) {
+	var OAIGPTResponseText, OAIGPTReasoningText string
+	var OAIGPTUsage *OpenAI.Usage
+
+	model := OAIGPTPrompt.Model
+
+	startTime := time.Now()
+	isFirstToken := true
+
+	ctx, span := tracing.StartSpan(ctx, "llm.completion", tracing.AttributeModel.String(model))
+
 	OAIGPTStream, err := OAIClient.CreateChatCompletionStream(
-		context.Background(),
+		ctx,
 		OAIGPTPrompt,
 	)
 
 	if err != nil {
-		return "", err
+		metrics.LLMRequestDuration.WithLabelValues(model, "error").Observe(time.Since(startTime).Seconds())
+		tracing.EndSpan(span, err)
+		return "", "", err
 	}
 	defer OAIGPTStream.Close()
 
@@ -179,24 +282,42 @@ func GPTResponse(question string) (response string, err error) {
 		}
 
 		if err != nil {
-			return "", err
+			metrics.LLMRequestDuration.WithLabelValues(model, "error").Observe(time.Since(startTime).Seconds())
+			tracing.EndSpan(span, err)
+			return "", "", err
 		}
 
 		if len(OAIGPTResponse.Choices) > 0 {
-			OAIGPTResponseText = OAIGPTResponseText + OAIGPTResponse.Choices[0].Delta.Content
+			delta := OAIGPTResponse.Choices[0].Delta
+			if isFirstToken && len(delta.Content)+len(delta.ReasoningContent) > 0 {
+				metrics.LLMTimeToFirstToken.WithLabelValues(model).Observe(time.Since(startTime).Seconds())
+				span.SetAttributes(tracing.AttributeTTFT.Int64(time.Since(startTime).Milliseconds()))
+				span.AddEvent("first_token")
+				isFirstToken = false
+			}
+
+			OAIGPTResponseText = OAIGPTResponseText + delta.Content
+			OAIGPTReasoningText = OAIGPTReasoningText + delta.ReasoningContent
+		}
+
+		// Usage is Sent in The Last Chunk When Supported by The Endpoint
+		if OAIGPTResponse.Usage != nil {
+			OAIGPTUsage = OAIGPTResponse.Usage
 		}
 	}
 
-	ThinkingResponseRegex := regexp.MustCompile("[\\s\\S]*<\\/think>\\n?")
-	CleanThinkingResponse := ThinkingResponseRegex.ReplaceAllString(OAIGPTResponseText, "")
+	// Usage is Only Counted When The Stream is Completed, So a Failed
+	// Attempt Which is Retried is not Counted Twice
+	if OAIGPTUsage != nil {
+		metrics.LLMToken.WithLabelValues(model, "prompt").Add(float64(OAIGPTUsage.PromptTokens))
+		metrics.LLMToken.WithLabelValues(model, "completion").Add(float64(OAIGPTUsage.CompletionTokens))
+		gptAddUsage(ctx, OAIGPTUsage.PromptTokens, OAIGPTUsage.CompletionTokens)
+	}
 
-	OAIGPTResponseBuffer := strings.TrimSpace(CleanThinkingResponse)
-	OAIGPTResponseBuffer = strings.TrimLeft(OAIGPTResponseBuffer, "?\n")
-	OAIGPTResponseBuffer = strings.TrimLeft(OAIGPTResponseBuffer, "!\n")
-	OAIGPTResponseBuffer = strings.TrimLeft(OAIGPTResponseBuffer, ":\n")
-	OAIGPTResponseBuffer = strings.TrimLeft(OAIGPTResponseBuffer, "'\n")
-	OAIGPTResponseBuffer = strings.TrimLeft(OAIGPTResponseBuffer, ".\n")
-	OAIGPTResponseBuffer = strings.TrimLeft(OAIGPTResponseBuffer, "\n")
+	metrics.LLMRequestDuration.WithLabelValues(model, "success").Observe(time.Since(startTime).Seconds())
+	tracing.EndSpan(span, nil)
+
+	log.WithFields(log.Fields{log.FieldModel: model, log.FieldLatency: time.Since(startTime).Milliseconds()}).Println(log.LogLevelDebug, "OpenAI GPT Completion Finished")
 
-	return OAIGPTResponseBuffer, nil
+	return OAIGPTResponseText, OAIGPTReasoningText, nil
 }
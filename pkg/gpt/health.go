@@ -0,0 +1,36 @@
+package gpt
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+var healthHTTPClient = &http.Client{
+	Timeout: 10 * time.Second,
+}
+
+// GPTPing checks whether the OpenAI compatible endpoint is reachable. Any
+// response below server error status is considered reachable, since some
+// compatible servers are not implementing the models endpoint.
+func GPTPing(ctx context.Context) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, OAIHost+OAIHostPath+"/models", nil)
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Authorization", "Bearer "+OAIAPIKey)
+
+	response, err := healthHTTPClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode >= http.StatusInternalServerError {
+		return errors.New("OpenAI Endpoint Responded with Status " + response.Status)
+	}
+
+	return nil
+}
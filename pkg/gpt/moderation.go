@@ -0,0 +1,206 @@
+package gpt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/env"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/log"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/pii"
+)
+
+const (
+	ModerationActionRefuse string = "refuse"
+	ModerationActionWarn   string = "warn"
+	ModerationActionLog    string = "log"
+)
+
+type ModerationResult struct {
+	Flagged    bool
+	Action     string
+	Categories []string
+}
+
+type moderationRequest struct {
+	Input string `json:"input"`
+	Model string `json:"model,omitempty"`
+}
+
+type moderationResponse struct {
+	Results []struct {
+		Flagged        bool               `json:"flagged"`
+		Categories     map[string]bool    `json:"categories"`
+		CategoryScores map[string]float64 `json:"category_scores"`
+	} `json:"results"`
+}
+
+var moderationHTTPClient = &http.Client{
+	Timeout: 30 * time.Second,
+}
+
+var (
+	GPTModeration bool
+	GPTModerationModel,
+	GPTModerationAction string
+	GPTModerationThreshold  float64
+	GPTModerationThresholds map[string]float64
+)
+
+func init() {
+	var err error
+
+	GPTModeration, err = env.GetEnvBool("GPT_MODERATION")
+	if err != nil {
+		GPTModeration = false
+	}
+
+	GPTModerationModel, err = env.GetEnvString("GPT_MODERATION_MODEL")
+	if err != nil {
+		GPTModerationModel = "omni-moderation-latest"
+	}
+
+	GPTModerationAction, err = env.GetEnvString("GPT_MODERATION_ACTION")
+	if err != nil {
+		GPTModerationAction = ModerationActionRefuse
+	}
+
+	GPTModerationAction = strings.ToLower(GPTModerationAction)
+	switch GPTModerationAction {
+	case ModerationActionRefuse, ModerationActionWarn, ModerationActionLog:
+	default:
+		log.Println(log.LogLevelWarn, "Unknown GPT Moderation Action '"+GPTModerationAction+"', Fallback to '"+ModerationActionRefuse+"'")
+		GPTModerationAction = ModerationActionRefuse
+	}
+
+	GPTModerationThreshold, err = env.GetEnvFloat64("GPT_MODERATION_THRESHOLD")
+	if err != nil {
+		GPTModerationThreshold = 0
+	}
+
+	// Category Thresholds Are Formatted as "category=score,category=score"
+	GPTModerationThresholds = make(map[string]float64)
+
+	thresholds, err := env.GetEnvString("GPT_MODERATION_THRESHOLDS")
+	if err == nil {
+		for _, threshold := range strings.Split(thresholds, ",") {
+			category, score, isFound := strings.Cut(threshold, "=")
+			if !isFound {
+				continue
+			}
+
+			value, err := strconv.ParseFloat(strings.TrimSpace(score), 64)
+			if err != nil {
+				log.Println(log.LogLevelWarn, "Invalid GPT Moderation Threshold for Category '"+category+"'")
+				continue
+			}
+
+			GPTModerationThresholds[strings.ToLower(strings.TrimSpace(category))] = value
+		}
+	}
+}
+
+func moderationThreshold(category string) (float64, bool) {
+	if threshold, isExist := GPTModerationThresholds[category]; isExist {
+		return threshold, true
+	}
+
+	if GPTModerationThreshold > 0 {
+		return GPTModerationThreshold, true
+	}
+
+	return 0, false
+}
+
+// GPTModerate checks the text against the OpenAI compatible moderations
+// endpoint. When no threshold is configured for a category, the flag
+// returned by the endpoint is used as is.
+func GPTModerate(ctx context.Context, text string) (ModerationResult, error) {
+	result := ModerationResult{
+		Action: GPTModerationAction,
+	}
+
+	if !GPTModeration || len(strings.TrimSpace(text)) == 0 {
+		return result, nil
+	}
+
+	// Redact Personal Data Before Sending Text to The Moderation Endpoint,
+	// The Answer is Already Restored with The Original Personal Data
+	if PIIConfig := gptPII(ctx); PIIConfig.Enabled {
+		text, _ = pii.Redact(text, PIIConfig.Detectors)
+	}
+
+	body, err := json.Marshal(moderationRequest{
+		Input: text,
+		Model: GPTModerationModel,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, OAIHost+OAIHostPath+"/moderations", bytes.NewReader(body))
+	if err != nil {
+		return result, err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Authorization", "Bearer "+OAIAPIKey)
+
+	response, err := moderationHTTPClient.Do(request)
+	if err != nil {
+		return result, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return result, errors.New("GPT Moderation Request Failed with Status " + response.Status)
+	}
+
+	var moderation moderationResponse
+
+	err = json.NewDecoder(response.Body).Decode(&moderation)
+	if err != nil {
+		return result, err
+	}
+
+	flaggedCategories := make(map[string]bool)
+
+	for _, moderationResult := range moderation.Results {
+		categories := make(map[string]bool)
+		for category := range moderationResult.Categories {
+			categories[category] = true
+		}
+
+		for category := range moderationResult.CategoryScores {
+			categories[category] = true
+		}
+
+		for category := range categories {
+			isFlagged := moderationResult.Categories[category]
+
+			threshold, isExist := moderationThreshold(category)
+			if isExist {
+				isFlagged = moderationResult.CategoryScores[category] >= threshold
+			}
+
+			if isFlagged {
+				flaggedCategories[category] = true
+			}
+		}
+	}
+
+	for category := range flaggedCategories {
+		result.Categories = append(result.Categories, category)
+	}
+
+	sort.Strings(result.Categories)
+	result.Flagged = len(result.Categories) > 0
+
+	return result, nil
+}
@@ -0,0 +1,193 @@
+package gpt
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	OpenAI "github.com/sashabaranov/go-openai"
+
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/env"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/log"
+)
+
+const (
+	ErrorClassRateLimit string = "rate_limit"
+	ErrorClassServer    string = "server"
+	ErrorClassNetwork   string = "network"
+	ErrorClassAuth      string = "auth"
+	ErrorClassContent   string = "content"
+	ErrorClassCanceled  string = "canceled"
+	ErrorClassUnknown   string = "unknown"
+)
+
+var (
+	GPTRetryMax,
+	GPTRetryBackoff,
+	GPTRetryBackoffMax int
+)
+
+type retryAfterKey struct{}
+
+// retryAfterTransport keeps the Retry-After header of rate limited and
+// unavailable responses, since the OpenAI client does not expose response
+// headers on errors.
+type retryAfterTransport struct {
+	base http.RoundTripper
+}
+
+func (t *retryAfterTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	response, err := t.base.RoundTrip(request)
+	if err != nil {
+		return response, err
+	}
+
+	if response.StatusCode == http.StatusTooManyRequests || response.StatusCode == http.StatusServiceUnavailable {
+		if retryAfter, isExist := request.Context().Value(retryAfterKey{}).(*time.Duration); isExist {
+			*retryAfter = parseRetryAfter(response.Header.Get("Retry-After"))
+		}
+	}
+
+	return response, nil
+}
+
+func init() {
+	var err error
+
+	GPTRetryMax, err = env.GetEnvInt("GPT_RETRY_MAX")
+	if err != nil {
+		GPTRetryMax = 3
+	}
+
+	GPTRetryBackoff, err = env.GetEnvInt("GPT_RETRY_BACKOFF")
+	if err != nil {
+		GPTRetryBackoff = 1
+	}
+
+	GPTRetryBackoffMax, err = env.GetEnvInt("GPT_RETRY_BACKOFF_MAX")
+	if err != nil {
+		GPTRetryBackoffMax = 30
+	}
+}
+
+// parseRetryAfter supports both delay in seconds and HTTP date formats.
+func parseRetryAfter(value string) time.Duration {
+	if len(value) == 0 {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return time.Until(date)
+	}
+
+	return 0
+}
+
+func classifyStatusCode(statusCode int) string {
+	switch {
+	case statusCode == http.StatusTooManyRequests:
+		return ErrorClassRateLimit
+	case statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden:
+		return ErrorClassAuth
+	case statusCode >= http.StatusInternalServerError:
+		return ErrorClassServer
+	case statusCode >= http.StatusBadRequest:
+		return ErrorClassContent
+	default:
+		return ErrorClassUnknown
+	}
+}
+
+func ClassifyError(err error) string {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return ErrorClassCanceled
+	}
+
+	var apiError *OpenAI.APIError
+	if errors.As(err, &apiError) {
+		// Quota Exceeded is Reported as Rate Limit but Will Not Recover by Retrying
+		if code, isString := apiError.Code.(string); isString && code == "insufficient_quota" {
+			return ErrorClassAuth
+		}
+
+		return classifyStatusCode(apiError.HTTPStatusCode)
+	}
+
+	var requestError *OpenAI.RequestError
+	if errors.As(err, &requestError) {
+		return classifyStatusCode(requestError.HTTPStatusCode)
+	}
+
+	var netError net.Error
+	if errors.As(err, &netError) {
+		return ErrorClassNetwork
+	}
+
+	return ErrorClassUnknown
+}
+
+func isRetryable(class string) bool {
+	switch class {
+	case ErrorClassRateLimit, ErrorClassServer, ErrorClassNetwork:
+		return true
+	default:
+		return false
+	}
+}
+
+// gptRetry calls the function until it succeeds, the error is not
+// retryable or the retry limit is reached. The delay is doubled on each
+// retry, unless the endpoint asks for a longer delay with Retry-After.
+func gptRetry(ctx context.Context, call func(ctx context.Context) error) error {
+	var err error
+
+	for attempt := 0; ; attempt++ {
+		var retryAfter time.Duration
+
+		err = call(context.WithValue(ctx, retryAfterKey{}, &retryAfter))
+		if err == nil {
+			return nil
+		}
+
+		class := ClassifyError(err)
+		if !isRetryable(class) || attempt >= GPTRetryMax {
+			return err
+		}
+
+		// Limit The Multiplier to Keep The Delay from Overflowing
+		multiplier := attempt
+		if multiplier > 10 {
+			multiplier = 10
+		}
+
+		delay := time.Duration(GPTRetryBackoff) * time.Second * time.Duration(1<<multiplier)
+		if retryAfter > delay {
+			delay = retryAfter
+		}
+
+		maxDelay := time.Duration(GPTRetryBackoffMax) * time.Second
+		if delay > maxDelay {
+			// Do not Wait Longer than Allowed When Endpoint Asks for It
+			if retryAfter > maxDelay {
+				return err
+			}
+
+			delay = maxDelay
+		}
+
+		log.WithError(err).WithFields(log.Fields{"class": class, "attempt": attempt + 1, "delay": delay.String()}).Println(log.LogLevelWarn, "Retrying OpenAI GPT Request")
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
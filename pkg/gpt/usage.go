@@ -0,0 +1,26 @@
+package gpt
+
+import (
+	"context"
+)
+
+// GPTUsage is the token usage of all completions made with a context
+// returned by GPTWithUsage.
+type GPTUsage struct {
+	PromptTokens     int
+	CompletionTokens int
+}
+
+type usageKey struct{}
+
+func GPTWithUsage(ctx context.Context) (context.Context, *GPTUsage) {
+	usage := &GPTUsage{}
+	return context.WithValue(ctx, usageKey{}, usage), usage
+}
+
+func gptAddUsage(ctx context.Context, promptTokens int, completionTokens int) {
+	if usage, isExist := ctx.Value(usageKey{}).(*GPTUsage); isExist {
+		usage.PromptTokens += promptTokens
+		usage.CompletionTokens += completionTokens
+	}
+}
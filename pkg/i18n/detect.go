@@ -0,0 +1,151 @@
+package i18n
+
+import (
+	"strings"
+	"unicode"
+)
+
+var languageNames = map[string]string{
+	"ar": "Arabic",
+	"de": "German",
+	"en": "English",
+	"es": "Spanish",
+	"fr": "French",
+	"hi": "Hindi",
+	"id": "Indonesian",
+	"it": "Italian",
+	"ja": "Japanese",
+	"jv": "Javanese",
+	"ko": "Korean",
+	"ms": "Malay",
+	"nl": "Dutch",
+	"pt": "Portuguese",
+	"ru": "Russian",
+	"th": "Thai",
+	"tr": "Turkish",
+	"vi": "Vietnamese",
+	"zh": "Chinese",
+}
+
+var languageScripts = []struct {
+	Language string
+	Script   *unicode.RangeTable
+}{
+	{"ja", unicode.Hiragana},
+	{"ja", unicode.Katakana},
+	{"ko", unicode.Hangul},
+	{"zh", unicode.Han},
+	{"th", unicode.Thai},
+	{"ar", unicode.Arabic},
+	{"hi", unicode.Devanagari},
+	{"ru", unicode.Cyrillic},
+}
+
+var languageStopwords = map[string][]string{
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ich", "du", "wie", "was", "ein", "eine", "mit", "für", "auf", "warum", "bitte"},
+	"en": {"the", "is", "are", "and", "what", "how", "why", "you", "to", "of", "in", "for", "with", "can", "please", "this", "that", "do", "does"},
+	"es": {"el", "la", "los", "las", "es", "y", "que", "qué", "cómo", "por", "para", "con", "una", "un", "no", "está", "puedes"},
+	"fr": {"le", "la", "les", "est", "et", "que", "quoi", "comment", "pourquoi", "pour", "avec", "une", "un", "pas", "je", "vous", "des"},
+	"id": {"yang", "dan", "di", "ke", "dari", "apa", "apakah", "bagaimana", "kenapa", "mengapa", "itu", "ini", "adalah", "tidak", "bisa", "saya", "aku", "kamu", "tolong", "dengan", "untuk", "ada", "gimana", "gak", "nggak"},
+	"it": {"il", "lo", "la", "gli", "è", "e", "che", "come", "perché", "per", "con", "una", "un", "non", "sono", "cosa"},
+	"jv": {"aku", "kowe", "opo", "piye", "ora", "ono", "karo", "sing", "iki", "kuwi", "ngopo", "wis"},
+	"ms": {"yang", "dan", "di", "ke", "dari", "apa", "bagaimana", "kenapa", "itu", "ini", "adalah", "tidak", "boleh", "saya", "awak", "anda", "dengan", "untuk", "ada", "sahaja", "sangat"},
+	"nl": {"de", "het", "een", "is", "en", "wat", "hoe", "waarom", "niet", "ik", "je", "met", "voor", "van", "zijn"},
+	"pt": {"o", "a", "os", "as", "é", "e", "que", "como", "por", "para", "com", "uma", "um", "não", "você", "está"},
+	"tr": {"ve", "bir", "bu", "ne", "nasıl", "neden", "için", "ile", "değil", "ben", "sen", "mi", "mı", "var"},
+	"vi": {"là", "và", "của", "có", "không", "gì", "như", "thế", "nào", "tại", "sao", "tôi", "bạn", "được", "này"},
+}
+
+func LanguageName(language string) string {
+	name, isExist := languageNames[strings.ToLower(strings.TrimSpace(language))]
+	if !isExist {
+		return strings.TrimSpace(language)
+	}
+
+	return name
+}
+
+func Detect(text string) string {
+	// Detect Language by Unicode Script for Non-Latin Text
+	scriptCount := make(map[string]int)
+	letterCount := 0
+
+	for _, char := range text {
+		if !unicode.IsLetter(char) {
+			continue
+		}
+
+		letterCount++
+		for _, languageScript := range languageScripts {
+			if unicode.Is(languageScript.Script, char) {
+				scriptCount[languageScript.Language]++
+				break
+			}
+		}
+	}
+
+	if letterCount == 0 {
+		return ""
+	}
+
+	// Japanese Text is Mixing Kana with Han, So Any Kana Wins Over Han
+	if scriptCount["ja"] > 0 {
+		return "ja"
+	}
+
+	bestLanguage, bestCount := "", 0
+	for language, count := range scriptCount {
+		if count > bestCount {
+			bestLanguage, bestCount = language, count
+		}
+	}
+
+	if bestCount*2 >= letterCount {
+		return bestLanguage
+	}
+
+	// Detect Language by Stopwords Frequency for Latin Text
+	words := strings.FieldsFunc(strings.ToLower(text), func(char rune) bool {
+		return !unicode.IsLetter(char) && char != '\''
+	})
+
+	wordScore := make(map[string]int)
+	for _, word := range words {
+		for language, stopwords := range languageStopwords {
+			for _, stopword := range stopwords {
+				if word == stopword {
+					wordScore[language]++
+					break
+				}
+			}
+		}
+	}
+
+	bestLanguage, bestScore, isTie := "", 0, false
+	for language, score := range wordScore {
+		switch {
+		case score > bestScore:
+			bestLanguage, bestScore, isTie = language, score, false
+		case score == bestScore:
+			isTie = true
+		}
+	}
+
+	// Prefer Indonesian Over Malay When Both Are Scoring The Same
+	if isTie && wordScore["id"] == bestScore && wordScore["ms"] == bestScore {
+		isTie = false
+		for language, score := range wordScore {
+			if score == bestScore && language != "id" && language != "ms" {
+				isTie = true
+			}
+		}
+
+		bestLanguage = "id"
+	}
+
+	if bestScore == 0 || isTie {
+		return ""
+	}
+
+	return bestLanguage
+}
@@ -0,0 +1,38 @@
+package i18n
+
+import (
+	"testing"
+)
+
+func TestDetect(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"English", "What is the capital of France and why is it famous?", "en"},
+		{"English Lowercase", "how do you make a cup of coffee", "en"},
+		{"Indonesian", "Apakah kamu bisa menjelaskan apa itu lubang hitam?", "id"},
+		{"Indonesian Informal", "gimana cara masak nasi goreng yang enak", "id"},
+		{"Indonesian Over Malay", "apa itu dan bagaimana", "id"},
+		{"Mixed Indonesian with English Terms", "Tolong jelaskan apa itu machine learning dan deep learning", "id"},
+		{"Mixed English with Indonesian Terms", "What is the best way to cook rendang and sambal?", "en"},
+		{"Mixed English with Han Word", "What does 你好 mean in the chat?", "en"},
+		{"Mixed Equal Score", "the yang", ""},
+		{"Japanese", "東京はどこですか", "ja"},
+		{"Empty", "", ""},
+		{"Whitespace", "   ", ""},
+		{"Numbers and Symbols", "123 + 456 = ?", ""},
+		{"Short Word without Stopword", "ok", ""},
+		{"Short Stopword", "why", "en"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result := Detect(testCase.input)
+			if result != testCase.expected {
+				t.Errorf("Detect(%q) = %q, expected %q", testCase.input, result, testCase.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/env"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/log"
+)
+
+var DefaultLanguage string
+
+type messageKey string
+
+const (
+	MessageBlockedWord         messageKey = "blocked_word"
+	MessageFailedResponse      messageKey = "failed_response"
+	MessageReasoningPrefix     messageKey = "reasoning_prefix"
+	MessageCodeAttachment      messageKey = "code_attachment"
+	MessageLanguageCurrent     messageKey = "language_current"
+	MessageLanguageChanged     messageKey = "language_changed"
+	MessageLanguageReset       messageKey = "language_reset"
+	MessageLanguageUnsupported messageKey = "language_unsupported"
+	MessageTranslateUsage      messageKey = "translate_usage"
+	MessageModerationRefused   messageKey = "moderation_refused"
+	MessageModerationWarning   messageKey = "moderation_warning"
+	MessageLateReply           messageKey = "late_reply"
+	MessageAdminOnly           messageKey = "admin_only"
+	MessageUnbanUsage          messageKey = "unban_usage"
+	MessageUnbanSuccess        messageKey = "unban_success"
+	MessageUnbanNotFound       messageKey = "unban_not_found"
+	MessageBanList             messageKey = "ban_list"
+	MessageBanListEmpty        messageKey = "ban_list_empty"
+	MessageReloadSuccess       messageKey = "reload_success"
+	MessageReloadNoChange      messageKey = "reload_no_change"
+	MessageReloadFailed        messageKey = "reload_failed"
+	MessageLogout              messageKey = "logout"
+)
+
+var catalog = map[string]map[messageKey]string{
+	"en": {
+		MessageBlockedWord:         "Sorry, the AI can not response due to it is containing some blocked word 🥺",
+		MessageFailedResponse:      "Sorry, the AI can not response for this time. Please try again after a few moment 🥺",
+		MessageReasoningPrefix:     "💭 *Reasoning*",
+		MessageCodeAttachment:      "📎 _See attached file %s_",
+		MessageLanguageCurrent:     "Current language for this chat is *%s*",
+		MessageLanguageChanged:     "Language for this chat has been changed to *%s*",
+		MessageLanguageReset:       "Language for this chat has been reset to default *%s*",
+		MessageLanguageUnsupported: "Sorry, language *%s* is not supported. Available languages are %s",
+		MessageTranslateUsage:      "Usage: *%s /translate <language> <text>* or reply to a message with *%s /translate <language>*",
+		MessageModerationRefused:   "Sorry, the AI can not response due to it is containing sensitive content 🥺",
+		MessageModerationWarning:   "⚠️ _This conversation may contain sensitive content (%s)_",
+		MessageLateReply:           "_Sorry for the late reply_ 🙏",
+		MessageAdminOnly:           "Sorry, this command is only available for admin 🙏",
+		MessageUnbanUsage:          "Usage: *%s /unban <phone number>*",
+		MessageUnbanSuccess:        "Ban for *%s* has been lifted",
+		MessageUnbanNotFound:       "There is no ban for *%s*",
+		MessageBanList:             "*Banned Senders*",
+		MessageBanListEmpty:        "There is no banned sender",
+		MessageReloadSuccess:       "Configuration has been reloaded, changed settings: %s",
+		MessageReloadNoChange:      "Configuration has been reloaded without any change",
+		MessageReloadFailed:        "Failed to reload configuration, current configuration is kept: %s",
+		MessageLogout:              "This account is logging out and will stop answering until it is logged-in again",
+	},
+	"id": {
+		MessageBlockedWord:         "Maaf, AI tidak dapat merespon karena mengandung kata yang diblokir 🥺",
+		MessageFailedResponse:      "Maaf, AI tidak dapat merespon untuk saat ini. Silakan coba lagi beberapa saat lagi 🥺",
+		MessageReasoningPrefix:     "💭 *Penalaran*",
+		MessageCodeAttachment:      "📎 _Lihat berkas terlampir %s_",
+		MessageLanguageCurrent:     "Bahasa untuk obrolan ini adalah *%s*",
+		MessageLanguageChanged:     "Bahasa untuk obrolan ini telah diubah menjadi *%s*",
+		MessageLanguageReset:       "Bahasa untuk obrolan ini telah dikembalikan ke bawaan *%s*",
+		MessageLanguageUnsupported: "Maaf, bahasa *%s* tidak didukung. Bahasa yang tersedia adalah %s",
+		MessageTranslateUsage:      "Penggunaan: *%s /translate <bahasa> <teks>* atau balas sebuah pesan dengan *%s /translate <bahasa>*",
+		MessageModerationRefused:   "Maaf, AI tidak dapat merespon karena mengandung konten sensitif 🥺",
+		MessageModerationWarning:   "⚠️ _Percakapan ini mungkin mengandung konten sensitif (%s)_",
+		MessageLateReply:           "_Maaf atas keterlambatan balasan_ 🙏",
+		MessageAdminOnly:           "Maaf, perintah ini hanya tersedia untuk admin 🙏",
+		MessageUnbanUsage:          "Penggunaan: *%s /unban <nomor telepon>*",
+		MessageUnbanSuccess:        "Blokir untuk *%s* telah dicabut",
+		MessageUnbanNotFound:       "Tidak ada blokir untuk *%s*",
+		MessageBanList:             "*Pengirim yang Diblokir*",
+		MessageBanListEmpty:        "Tidak ada pengirim yang diblokir",
+		MessageReloadSuccess:       "Konfigurasi telah dimuat ulang, pengaturan yang berubah: %s",
+		MessageReloadNoChange:      "Konfigurasi telah dimuat ulang tanpa perubahan",
+		MessageReloadFailed:        "Gagal memuat ulang konfigurasi, konfigurasi saat ini tetap digunakan: %s",
+		MessageLogout:              "Akun ini sedang keluar dan tidak akan menjawab sampai masuk kembali",
+	},
+}
+
+func init() {
+	var err error
+
+	DefaultLanguage, err = env.GetEnvString("WHATSAPP_GPT_LANGUAGE")
+	if err != nil {
+		DefaultLanguage = "en"
+	}
+
+	DefaultLanguage = strings.ToLower(DefaultLanguage)
+	if !IsSupported(DefaultLanguage) {
+		log.Println(log.LogLevelWarn, "Unknown WhatsApp GPT Language '"+DefaultLanguage+"', Fallback to 'en'")
+		DefaultLanguage = "en"
+	}
+}
+
+func IsSupported(language string) bool {
+	_, isExist := catalog[strings.ToLower(language)]
+	return isExist
+}
+
+func Languages() []string {
+	languages := make([]string, 0, len(catalog))
+	for language := range catalog {
+		languages = append(languages, language)
+	}
+
+	sort.Strings(languages)
+	return languages
+}
+
+func Message(language string, key messageKey, args ...interface{}) string {
+	messages, isExist := catalog[strings.ToLower(language)]
+	if !isExist {
+		messages = catalog[DefaultLanguage]
+	}
+
+	message, isExist := messages[key]
+	if !isExist {
+		// Fallback to English Message When Translation is Missing
+		message = catalog["en"][key]
+	}
+
+	if len(args) > 0 {
+		return fmt.Sprintf(message, args...)
+	}
+
+	return message
+}
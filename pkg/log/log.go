@@ -2,12 +2,21 @@ package log
 
 import (
 	"os"
+	"strings"
 
 	"github.com/sirupsen/logrus"
+
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/env"
 )
 
 var logger *logrus.Logger
 
+// Loggers for WhatsApp Client Library Which Have Their Own Level
+var (
+	loggerClient,
+	loggerDatastore *logrus.Logger
+)
+
 type logLevel string
 
 const (
@@ -20,35 +29,108 @@ const (
 	LogLevelInfo  logLevel = "info"
 )
 
+const (
+	LogFormatText string = "text"
+	LogFormatJSON string = "json"
+)
+
+// Common Field Names Used Across Packages
+// So Log Entries Can be Queried Consistently
+const (
+	FieldChat      string = "chat"
+	FieldSender    string = "sender"
+	FieldMessageID string = "message_id"
+	FieldModel     string = "model"
+	FieldLatency   string = "latency_ms"
+	FieldError     string = "error"
+)
+
+type Fields map[string]interface{}
+
+type Entry struct {
+	entry *logrus.Entry
+}
+
 func init() {
-	logger = logrus.New()
+	var formatter logrus.Formatter
 
-	logger.SetFormatter(&logrus.TextFormatter{
-		ForceColors:   true,
-		FullTimestamp: true,
-	})
+	logFormat, err := env.GetEnvString("LOG_FORMAT")
+	if err != nil {
+		logFormat = LogFormatText
+	}
 
-	logger.SetOutput(os.Stdout)
-	logger.SetLevel(logrus.InfoLevel)
+	switch strings.ToLower(logFormat) {
+	case LogFormatJSON:
+		formatter = &logrus.JSONFormatter{}
+	default:
+		formatter = &logrus.TextFormatter{
+			ForceColors:   true,
+			FullTimestamp: true,
+		}
+	}
+
+	logger = newLogger(formatter, "LOG_LEVEL", logrus.InfoLevel)
+	loggerClient = newLogger(formatter, "LOG_LEVEL_CLIENT", logrus.WarnLevel)
+	loggerDatastore = newLogger(formatter, "LOG_LEVEL_DATASTORE", logrus.WarnLevel)
+}
+
+func newLogger(formatter logrus.Formatter, levelEnv string, defaultLevel logrus.Level) *logrus.Logger {
+	componentLogger := logrus.New()
+
+	componentLogger.SetFormatter(formatter)
+	componentLogger.SetOutput(os.Stdout)
+	componentLogger.SetLevel(defaultLevel)
+
+	logLevel, err := env.GetEnvString(levelEnv)
+	if err == nil {
+		level, err := logrus.ParseLevel(logLevel)
+		if err != nil {
+			componentLogger.Warnln("Unknown Log Level '" + logLevel + "' for " + levelEnv + ", Fallback to '" + defaultLevel.String() + "'")
+		} else {
+			componentLogger.SetLevel(level)
+		}
+	}
+
+	return componentLogger
+}
+
+func WithFields(fields Fields) *Entry {
+	return &Entry{entry: logrus.NewEntry(logger).WithFields(logrus.Fields(fields))}
+}
+
+func WithError(err error) *Entry {
+	return WithFields(Fields{FieldError: err.Error()})
+}
+
+func (e *Entry) WithFields(fields Fields) *Entry {
+	return &Entry{entry: e.entry.WithFields(logrus.Fields(fields))}
+}
+
+func (e *Entry) WithError(err error) *Entry {
+	return e.WithFields(Fields{FieldError: err.Error()})
+}
+
+func (e *Entry) Println(level logLevel, message interface{}) {
+	switch level {
+	case "panic":
+		e.entry.Panicln(message)
+	case "fatal":
+		e.entry.Fatalln(message)
+	case "error":
+		e.entry.Errorln(message)
+	case "warn":
+		e.entry.Warnln(message)
+	case "debug":
+		e.entry.Debugln(message)
+	case "trace":
+		e.entry.Traceln(message)
+	default:
+		e.entry.Infoln(message)
+	}
 }
 
 func Println(level logLevel, message interface{}) {
 	if logger != nil {
-		switch level {
-		case "panic":
-			logger.Panicln(message)
-		case "fatal":
-			logger.Fatalln(message)
-		case "error":
-			logger.Errorln(message)
-		case "warn":
-			logger.Warnln(message)
-		case "debug":
-			logger.Debugln(message)
-		case "trace":
-			logger.Traceln(message)
-		default:
-			logger.Infoln(message)
-		}
+		WithFields(nil).Println(level, message)
 	}
 }
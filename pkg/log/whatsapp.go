@@ -0,0 +1,51 @@
+package log
+
+import (
+	"github.com/sirupsen/logrus"
+	waLog "go.mau.fi/whatsmeow/util/log"
+)
+
+const (
+	ComponentClient    string = "client"
+	ComponentDatastore string = "datastore"
+)
+
+// whatsAppLogger routes WhatsApp client library logs into our logger
+// with the level configured for the component. Messages are formatted by
+// the entry only when the level is enabled.
+type whatsAppLogger struct {
+	entry *logrus.Entry
+}
+
+func WhatsAppLogger(component string) waLog.Logger {
+	componentLogger := loggerClient
+	if component == ComponentDatastore {
+		componentLogger = loggerDatastore
+	}
+
+	return &whatsAppLogger{entry: logrus.NewEntry(componentLogger).WithField("component", component)}
+}
+
+func (l *whatsAppLogger) Errorf(message string, args ...interface{}) {
+	l.entry.Errorf(message, args...)
+}
+
+func (l *whatsAppLogger) Warnf(message string, args ...interface{}) {
+	l.entry.Warnf(message, args...)
+}
+
+func (l *whatsAppLogger) Infof(message string, args ...interface{}) {
+	l.entry.Infof(message, args...)
+}
+
+func (l *whatsAppLogger) Debugf(message string, args ...interface{}) {
+	l.entry.Debugf(message, args...)
+}
+
+func (l *whatsAppLogger) Sub(module string) waLog.Logger {
+	if parent, isExist := l.entry.Data["module"].(string); isExist {
+		module = parent + "/" + module
+	}
+
+	return &whatsAppLogger{entry: l.entry.WithField("module", module)}
+}
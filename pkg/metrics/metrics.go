@@ -0,0 +1,89 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace string = "whatsapp_gpt"
+
+const (
+	ChatTypePrivate string = "private"
+	ChatTypeGroup   string = "group"
+)
+
+const (
+	OutcomeAnswered string = "answered"
+	OutcomeRefused  string = "refused"
+)
+
+const (
+	SendTypeMessage   string = "message"
+	SendTypeReasoning string = "reasoning"
+	SendTypeDocument  string = "document"
+	SendTypeReaction  string = "reaction"
+)
+
+var (
+	QuestionReceived = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "questions_received_total",
+		Help:      "Number of tagged questions received.",
+	}, []string{"chat_type"})
+
+	QuestionAnswered = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "questions_answered_total",
+		Help:      "Number of questions answered by the model and delivered by outcome, refused when the answer is replaced with a refusal.",
+	}, []string{"chat_type", "outcome"})
+
+	QuestionBlocked = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "questions_blocked_total",
+		Help:      "Number of questions refused or ignored by reason.",
+	}, []string{"chat_type", "reason"})
+
+	LLMRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "llm_request_duration_seconds",
+		Help:      "Duration of LLM completion requests.",
+		Buckets:   []float64{0.5, 1, 2, 5, 10, 20, 30, 60, 120},
+	}, []string{"model", "status"})
+
+	LLMTimeToFirstToken = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "llm_time_to_first_token_seconds",
+		Help:      "Time until the first streamed token of LLM completion requests.",
+		Buckets:   []float64{0.1, 0.25, 0.5, 1, 2, 5, 10, 20},
+	}, []string{"model"})
+
+	LLMToken = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "llm_tokens_total",
+		Help:      "Number of tokens used by model and token type.",
+	}, []string{"model", "type"})
+
+	SendFailure = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "send_failures_total",
+		Help:      "Number of failed WhatsApp sends by type.",
+	}, []string{"type"})
+
+	Reconnect = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "reconnects_total",
+		Help:      "Number of WhatsApp client reconnections.",
+	})
+)
+
+// RegisterQueueDepth exposes the current question queue depth using the
+// given function, so this package does not depend on the queue itself.
+func RegisterQueueDepth(depth func() int) {
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "queue_depth",
+		Help:      "Number of questions waiting in the queue.",
+	}, func() float64 {
+		return float64(depth())
+	})
+}
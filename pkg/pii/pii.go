@@ -0,0 +1,151 @@
+package pii
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	DetectorEmail   string = "email"
+	DetectorNIK     string = "nik"
+	DetectorPhone   string = "phone"
+	DetectorAccount string = "account"
+)
+
+type Config struct {
+	Enabled   bool
+	Restore   bool
+	Detectors []string
+}
+
+type detector struct {
+	Name     string
+	Label    string
+	Regex    *regexp.Regexp
+	Validate func(string) bool
+}
+
+// Detectors are applied in this order, so the more specific patterns
+// have to come before the generic digit sequences.
+var detectors = []detector{
+	{
+		Name:  DetectorEmail,
+		Label: "EMAIL",
+		Regex: regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+	},
+	{
+		Name:     DetectorNIK,
+		Label:    "NIK",
+		Regex:    regexp.MustCompile(`\b\d{16}\b`),
+		Validate: isValidNIK,
+	},
+	{
+		Name:  DetectorPhone,
+		Label: "PHONE",
+		Regex: regexp.MustCompile(`(?:\+62|\b62|\b0)[\s\-]?8\d{1,3}[\s\-]?\d{3,4}[\s\-]?\d{3,5}\b|\+\d{1,3}[\s\-]?\d{2,4}[\s\-]?\d{3,4}[\s\-]?\d{3,5}\b`),
+	},
+	{
+		Name:  DetectorAccount,
+		Label: "ACCOUNT",
+		Regex: regexp.MustCompile(`\b\d{10,18}\b|\b\d{3,4}(?:[\s\-]\d{3,4}){2,3}\b`),
+	},
+}
+
+var Detectors = []string{DetectorEmail, DetectorNIK, DetectorPhone, DetectorAccount}
+
+// isValidNIK checks the Indonesian NIK structure, which is a 2 digits
+// province code, 4 digits regency and district codes, a birth date where
+// female day is added by 40, and 4 digits sequence number.
+func isValidNIK(value string) bool {
+	province, _ := strconv.Atoi(value[0:2])
+	if province < 11 || province > 94 {
+		return false
+	}
+
+	day, _ := strconv.Atoi(value[6:8])
+	if day > 40 {
+		day = day - 40
+	}
+
+	if day < 1 || day > 31 {
+		return false
+	}
+
+	month, _ := strconv.Atoi(value[8:10])
+	if month < 1 || month > 12 {
+		return false
+	}
+
+	return value[12:16] != "0000"
+}
+
+func isEnabled(detectors []string, name string) bool {
+	for _, detector := range detectors {
+		if strings.EqualFold(strings.TrimSpace(detector), name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsDetector reports whether the name is one of the known detectors.
+func IsDetector(name string) bool {
+	return isEnabled(Detectors, name)
+}
+
+// Redact replaces detected personal data in the text with placeholders such
+// as "[EMAIL_1]" and returns the mapping of placeholders to original values.
+// The same value is always replaced with the same placeholder.
+func Redact(text string, enabledDetectors []string) (string, map[string]string) {
+	texts, vault := RedactAll([]string{text}, enabledDetectors)
+	return texts[0], vault
+}
+
+// RedactAll redacts several texts sharing the same placeholders, so a value
+// appearing in more than one text is replaced with the same placeholder.
+func RedactAll(texts []string, enabledDetectors []string) ([]string, map[string]string) {
+	vault := make(map[string]string)
+	placeholders := make(map[string]string)
+	counters := make(map[string]int)
+
+	redacted := make([]string, len(texts))
+	copy(redacted, texts)
+
+	for _, detector := range detectors {
+		if !isEnabled(enabledDetectors, detector.Name) {
+			continue
+		}
+
+		for i := range redacted {
+			redacted[i] = detector.Regex.ReplaceAllStringFunc(redacted[i], func(value string) string {
+				if detector.Validate != nil && !detector.Validate(value) {
+					return value
+				}
+
+				if placeholder, isExist := placeholders[value]; isExist {
+					return placeholder
+				}
+
+				counters[detector.Label]++
+
+				placeholder := "[" + detector.Label + "_" + strconv.Itoa(counters[detector.Label]) + "]"
+				placeholders[value] = placeholder
+				vault[placeholder] = value
+
+				return placeholder
+			})
+		}
+	}
+
+	return redacted, vault
+}
+
+func Restore(text string, vault map[string]string) string {
+	for placeholder, value := range vault {
+		text = strings.ReplaceAll(text, placeholder, value)
+	}
+
+	return text
+}
@@ -0,0 +1,116 @@
+package pii
+
+import (
+	"testing"
+)
+
+func TestRedactDetectors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"Email", "send it to budi.santoso@example.co.id please", "send it to [EMAIL_1] please"},
+		{"Email with Plus", "my mail is user+bot@mail.com", "my mail is [EMAIL_1]"},
+		{"Phone Local", "call me at 081234567890", "call me at [PHONE_1]"},
+		{"Phone Local with Dash", "call me at 0812-3456-7890", "call me at [PHONE_1]"},
+		{"Phone Country Code", "my number +6281234567890", "my number [PHONE_1]"},
+		{"Phone Country Code with Space", "my number +62 812 3456 7890", "my number [PHONE_1]"},
+		{"Phone International", "call +1 415 555 2671 now", "call [PHONE_1] now"},
+		{"NIK Male", "NIK saya 3273011503900001", "NIK saya [NIK_1]"},
+		{"NIK Female", "NIK saya 3273015503900002", "NIK saya [NIK_1]"},
+		{"Account Number", "transfer ke rekening 1234567890", "transfer ke rekening [ACCOUNT_1]"},
+		{"Account Number with Dash", "rekening 123-456-7890", "rekening [ACCOUNT_1]"},
+		{"Account Number with Space", "rekening 1234 5678 9012 3456", "rekening [ACCOUNT_1]"},
+		{"Invalid NIK as Account", "kode 9973011503900001", "kode [ACCOUNT_1]"},
+		{"Short Number is Kept", "I have 12345 apples", "I have 12345 apples"},
+		{"Year is Kept", "born in 1990", "born in 1990"},
+		{"Plain Text is Kept", "what is a black hole?", "what is a black hole?"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			result, _ := Redact(testCase.input, Detectors)
+			if result != testCase.expected {
+				t.Errorf("Redact(%q) = %q, expected %q", testCase.input, result, testCase.expected)
+			}
+		})
+	}
+}
+
+func TestRedactSameValue(t *testing.T) {
+	result, vault := Redact("a@b.com, c@d.com, a@b.com", Detectors)
+
+	expected := "[EMAIL_1], [EMAIL_2], [EMAIL_1]"
+	if result != expected {
+		t.Errorf("Redact() = %q, expected %q", result, expected)
+	}
+
+	if len(vault) != 2 {
+		t.Errorf("Redact() vault has %d entries, expected 2", len(vault))
+	}
+}
+
+func TestRedactAllSharedPlaceholders(t *testing.T) {
+	result, vault := RedactAll([]string{"mail a@b.com", "sent to c@d.com and a@b.com"}, Detectors)
+
+	expected := []string{"mail [EMAIL_1]", "sent to [EMAIL_2] and [EMAIL_1]"}
+	for i := range expected {
+		if result[i] != expected[i] {
+			t.Errorf("RedactAll()[%d] = %q, expected %q", i, result[i], expected[i])
+		}
+	}
+
+	if len(vault) != 2 {
+		t.Errorf("RedactAll() vault has %d entries, expected 2", len(vault))
+	}
+}
+
+func TestRedactSelectedDetectors(t *testing.T) {
+	input := "mail a@b.com or call 081234567890"
+
+	result, _ := Redact(input, []string{DetectorPhone})
+
+	expected := "mail a@b.com or call [PHONE_1]"
+	if result != expected {
+		t.Errorf("Redact() = %q, expected %q", result, expected)
+	}
+}
+
+func TestIsValidNIK(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected bool
+	}{
+		{"3273011503900001", true},
+		{"3273015503900001", true},
+		{"0073011503900001", false},
+		{"3273013203900001", false},
+		{"3273011513900001", false},
+		{"3273011503900000", false},
+	}
+
+	for _, testCase := range testCases {
+		if result := isValidNIK(testCase.value); result != testCase.expected {
+			t.Errorf("isValidNIK(%q) = %v, expected %v", testCase.value, result, testCase.expected)
+		}
+	}
+}
+
+func TestRestore(t *testing.T) {
+	input := "email budi@example.com and phone 081234567890"
+
+	redacted, vault := Redact(input, Detectors)
+	answer := "I will contact " + "[PHONE_1]" + " and " + "[EMAIL_1]"
+
+	result := Restore(answer, vault)
+
+	expected := "I will contact 081234567890 and budi@example.com"
+	if result != expected {
+		t.Errorf("Restore() = %q, expected %q", result, expected)
+	}
+
+	if Restore(redacted, vault) != input {
+		t.Errorf("Restore(Redact()) = %q, expected %q", Restore(redacted, vault), input)
+	}
+}
@@ -0,0 +1,227 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	pkgDatastore "github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/datastore"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/env"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/gpt"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/log"
+	pkgWhatsApp "github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/whatsapp"
+)
+
+var (
+	HTTPServerEnable  bool
+	HTTPServerAddress string
+)
+
+type checkResult struct {
+	Status string                 `json:"status"`
+	Error  string                 `json:"error,omitempty"`
+	Detail map[string]interface{} `json:"detail,omitempty"`
+}
+
+type healthResponse struct {
+	Status string                 `json:"status"`
+	Time   time.Time              `json:"time"`
+	Checks map[string]checkResult `json:"checks,omitempty"`
+}
+
+// LLM Endpoint Check is Cached to Avoid Calling
+// The Endpoint on Every Probe
+var (
+	llmCheckMutex  sync.Mutex
+	llmCheckAt     time.Time
+	llmCheckResult checkResult
+	llmCheckDone   chan struct{}
+)
+
+const llmCheckTTL = 30 * time.Second
+
+func init() {
+	var err error
+
+	HTTPServerEnable, err = env.GetEnvBool("HTTP_SERVER_ENABLE")
+	if err != nil {
+		HTTPServerEnable = false
+	}
+
+	HTTPServerAddress, err = env.GetEnvString("HTTP_SERVER_ADDRESS")
+	if err != nil {
+		HTTPServerAddress = "0.0.0.0:8080"
+	}
+}
+
+func Start() *http.Server {
+	if !HTTPServerEnable {
+		return nil
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", handleHealth)
+	mux.HandleFunc("/readyz", handleReady)
+	mux.Handle("/metrics", promhttp.Handler())
+
+	server := &http.Server{
+		Addr:              HTTPServerAddress,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		log.WithFields(log.Fields{"address": HTTPServerAddress}).Println(log.LogLevelInfo, "Starting HTTP Server")
+
+		err := server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.WithError(err).Println(log.LogLevelError, "Failed to Start HTTP Server")
+		}
+	}()
+
+	return server
+}
+
+func Stop(server *http.Server) {
+	if server == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := server.Shutdown(ctx)
+	if err != nil {
+		log.WithError(err).Println(log.LogLevelWarn, "Failed to Stop HTTP Server Gracefully")
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, healthResponse{
+		Status: "ok",
+		Time:   time.Now(),
+	})
+}
+
+func handleReady(w http.ResponseWriter, r *http.Request) {
+	checks := map[string]checkResult{
+		"whatsapp":  checkWhatsApp(),
+		"datastore": checkDatastore(r.Context()),
+		"llm":       checkLLM(r.Context()),
+	}
+
+	response := healthResponse{
+		Status: "ok",
+		Time:   time.Now(),
+		Checks: checks,
+	}
+
+	status := http.StatusOK
+	for _, check := range checks {
+		if check.Status != "ok" {
+			response.Status = "fail"
+			status = http.StatusServiceUnavailable
+		}
+	}
+
+	writeJSON(w, status, response)
+}
+
+func checkWhatsApp() checkResult {
+	accounts := pkgWhatsApp.WhatsAppAccounts()
+	if len(accounts) == 0 {
+		return checkResult{
+			Status: "fail",
+			Error:  "No Logged-in WhatsApp Account",
+			Detail: map[string]interface{}{"state": pkgWhatsApp.ConnectionStateWaitingLogin},
+		}
+	}
+
+	result := checkResult{
+		Status: "ok",
+		Detail: map[string]interface{}{},
+	}
+
+	for _, account := range accounts {
+		isConnected, isLoggedIn := account.Client.IsConnected(), account.Client.IsLoggedIn()
+
+		result.Detail[pkgWhatsApp.WhatsAppMaskJID(account.JID)] = map[string]interface{}{
+			"connected": isConnected,
+			"logged_in": isLoggedIn,
+			"state":     account.ConnectionState(),
+		}
+
+		if !isConnected || !isLoggedIn {
+			result.Status = "fail"
+			result.Error = "WhatsApp Client is not Connected or Logged-in"
+		}
+	}
+
+	return result
+}
+
+func checkDatastore(ctx context.Context) checkResult {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	err := pkgDatastore.DB.PingContext(ctx)
+	if err != nil {
+		return checkResult{Status: "fail", Error: err.Error()}
+	}
+
+	return checkResult{Status: "ok"}
+}
+
+func checkLLM(ctx context.Context) checkResult {
+	llmCheckMutex.Lock()
+
+	if time.Since(llmCheckAt) < llmCheckTTL {
+		defer llmCheckMutex.Unlock()
+		return llmCheckResult
+	}
+
+	// Concurrent Probes Wait for The Running Check Instead of
+	// Calling The Endpoint Again
+	if done := llmCheckDone; done != nil {
+		llmCheckMutex.Unlock()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return checkResult{Status: "fail", Error: ctx.Err().Error()}
+		}
+
+		llmCheckMutex.Lock()
+		defer llmCheckMutex.Unlock()
+		return llmCheckResult
+	}
+
+	done := make(chan struct{})
+	llmCheckDone = done
+	llmCheckMutex.Unlock()
+
+	// The Lock is not Held While Calling The Endpoint
+	result := checkResult{Status: "ok"}
+	if err := gpt.GPTPing(ctx); err != nil {
+		result = checkResult{Status: "fail", Error: err.Error()}
+	}
+
+	llmCheckMutex.Lock()
+	llmCheckResult, llmCheckAt, llmCheckDone = result, time.Now(), nil
+	llmCheckMutex.Unlock()
+
+	close(done)
+	return result
+}
@@ -0,0 +1,105 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.34.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/env"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/log"
+)
+
+const tracerName string = "github.com/dimaskiddo/go-whatsapp-multidevice-gpt"
+
+const (
+	AttributeMessageID = attribute.Key("whatsapp.message.id")
+	AttributeChatType  = attribute.Key("whatsapp.chat.type")
+	AttributeIgnored   = attribute.Key("whatsapp.message.ignored")
+	AttributeSendType  = attribute.Key("whatsapp.send.type")
+	AttributeFlagged   = attribute.Key("moderation.flagged")
+	AttributeModel     = attribute.Key("llm.model")
+	AttributeTTFT      = attribute.Key("llm.time_to_first_token_ms")
+)
+
+var TracingEnable bool
+
+var provider *sdktrace.TracerProvider
+
+func init() {
+	var err error
+
+	TracingEnable, err = env.GetEnvBool("TRACING_ENABLE")
+	if err != nil {
+		TracingEnable = false
+	}
+}
+
+// Start initializes the OTLP exporter when tracing is enabled. The collector
+// endpoint and headers are configured with the standard OTEL_EXPORTER_OTLP_*
+// environment variables.
+func Start(ctx context.Context) {
+	if !TracingEnable {
+		return
+	}
+
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		log.WithError(err).Println(log.LogLevelError, "Failed to Create OpenTelemetry Trace Exporter")
+		return
+	}
+
+	// Service Name from OTEL_SERVICE_NAME Takes Precedence
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName("go-whatsapp-multidevice-gpt")),
+		resource.WithFromEnv(),
+		resource.WithTelemetrySDK(),
+	)
+	if err != nil {
+		res = resource.Default()
+	}
+
+	provider = sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+	)
+
+	otel.SetTracerProvider(provider)
+	log.Println(log.LogLevelInfo, "OpenTelemetry Tracing is Enabled")
+}
+
+// Stop flushes the remaining spans to the collector.
+func Stop() {
+	if provider == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := provider.Shutdown(ctx)
+	if err != nil {
+		log.WithError(err).Println(log.LogLevelWarn, "Failed to Flush OpenTelemetry Spans")
+	}
+}
+
+func StartSpan(ctx context.Context, name string, attributes ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attributes...))
+}
+
+// EndSpan records the error on the span when it is not nil and ends it.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	span.End()
+}
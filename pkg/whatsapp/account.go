@@ -0,0 +1,241 @@
+package whatsapp
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/store"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+
+	pkgDatastore "github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/datastore"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/gpt"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/log"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/pii"
+)
+
+// WhatsAppAccount is a logged-in WhatsApp device with its own client,
+// connection state and settings.
+type WhatsAppAccount struct {
+	JID    types.JID
+	Client *whatsmeow.Client
+
+	settings atomic.Pointer[whatsAppAccountSettings]
+
+	connectionState      string
+	connectionStateMutex sync.RWMutex
+	connectionEvents     chan connectionEvent
+}
+
+// whatsAppAccountSettings is the account settings from the datastore,
+// an empty tag or persona is using the global configuration and an empty
+// allow list is allowing everyone. The PII configuration is the global
+// configuration with the account overrides applied.
+type whatsAppAccountSettings struct {
+	Tag     *whatsAppTagConfig
+	Persona string
+	Allow   []types.JID
+	PII     pii.Config
+}
+
+var (
+	accounts      = make(map[string]*WhatsAppAccount)
+	accountsMutex sync.RWMutex
+)
+
+// WhatsAppAccounts returns the running accounts ordered by their JID.
+func WhatsAppAccounts() []*WhatsAppAccount {
+	accountsMutex.RLock()
+	defer accountsMutex.RUnlock()
+
+	var list []*WhatsAppAccount
+	for _, account := range accounts {
+		list = append(list, account)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].JID.String() < list[j].JID.String()
+	})
+
+	return list
+}
+
+func whatsAppAccountExists(jid types.JID) bool {
+	accountsMutex.RLock()
+	defer accountsMutex.RUnlock()
+
+	_, isExist := accounts[jid.String()]
+	return isExist
+}
+
+func whatsAppAddAccount(account *WhatsAppAccount) {
+	accountsMutex.Lock()
+	accounts[account.JID.String()] = account
+	accountsMutex.Unlock()
+}
+
+func whatsAppRemoveAccount(account *WhatsAppAccount) {
+	accountsMutex.Lock()
+	delete(accounts, account.JID.String())
+	accountsMutex.Unlock()
+}
+
+// whatsAppNewAccount creates the account client for a stored device and
+// registers the event handlers exactly once for the client.
+func whatsAppNewAccount(device *store.Device) *WhatsAppAccount {
+	account := &WhatsAppAccount{
+		JID:              device.ID.ToNonAD(),
+		Client:           WhatsAppNewClient(device),
+		connectionEvents: make(chan connectionEvent, 16),
+	}
+
+	settings, err := whatsAppLoadAccountSettings(account.JID)
+	if err != nil {
+		log.WithError(err).WithFields(account.logFields()).Println(log.LogLevelError, "Failed to Load WhatsApp Account Settings, Using Global Settings")
+		settings = &whatsAppAccountSettings{PII: gpt.GPTModelPII}
+	}
+
+	account.settings.Store(settings)
+
+	// Reconnection is Handled by The Supervisor
+	account.Client.EnableAutoReconnect = false
+
+	account.Client.AddEventHandler(account.supervisorHandler)
+	account.Client.AddEventHandler(func(event interface{}) {
+		WhatsAppHandler(account, event)
+	})
+
+	return account
+}
+
+func whatsAppLoadAccountSettings(jid types.JID) (*whatsAppAccountSettings, error) {
+	stored, err := pkgDatastore.GetAccount(context.Background(), jid.String())
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &whatsAppAccountSettings{
+		Persona: stored.Persona,
+		PII:     gpt.GPTModelPII,
+	}
+
+	if len(stored.PIIRedaction) > 0 {
+		settings.PII.Enabled, err = strconv.ParseBool(stored.PIIRedaction)
+		if err != nil {
+			return nil, errors.New("PII Redaction Setting '" + stored.PIIRedaction + "' is not a Boolean")
+		}
+	}
+
+	if len(stored.PIIRestore) > 0 {
+		settings.PII.Restore, err = strconv.ParseBool(stored.PIIRestore)
+		if err != nil {
+			return nil, errors.New("PII Restore Setting '" + stored.PIIRestore + "' is not a Boolean")
+		}
+	}
+
+	if len(stored.PIIDetectors) > 0 {
+		settings.PII.Detectors = stored.PIIDetectors
+	}
+
+	if tag := strings.TrimSpace(strings.ToLower(stored.Tag)); len(tag) > 0 {
+		regex, err := regexp.Compile("\\b(?i)(" + tag + " " + ")")
+		if err != nil {
+			return nil, err
+		}
+
+		settings.Tag = &whatsAppTagConfig{Tag: tag, Regex: regex}
+	}
+
+	for _, allow := range stored.Allow {
+		allowJID, err := WhatsAppParseJID(allow)
+		if err != nil {
+			return nil, err
+		}
+
+		settings.Allow = append(settings.Allow, allowJID.ToNonAD())
+	}
+
+	return settings, nil
+}
+
+func (s *whatsAppAccountSettings) Settings(account *WhatsAppAccount) map[string]string {
+	tag := ""
+	if s.Tag != nil {
+		tag = s.Tag.Tag
+	}
+
+	var allow []string
+	for _, allowJID := range s.Allow {
+		allow = append(allow, allowJID.String())
+	}
+
+	prefix := "ACCOUNT " + WhatsAppMaskJID(account.JID) + " "
+
+	return map[string]string{
+		prefix + "TAG":           tag,
+		prefix + "PERSONA":       s.Persona,
+		prefix + "ALLOW":         strings.Join(allow, ","),
+		prefix + "PII_REDACTION": strconv.FormatBool(s.PII.Enabled),
+		prefix + "PII_RESTORE":   strconv.FormatBool(s.PII.Restore),
+		prefix + "PII_DETECTORS": strings.Join(s.PII.Detectors, ","),
+	}
+}
+
+func (a *WhatsAppAccount) logFields() log.Fields {
+	return log.Fields{"account": WhatsAppMaskJID(a.JID)}
+}
+
+// Tag returns the account trigger tag or the global tag when the account
+// has no tag of its own.
+func (a *WhatsAppAccount) Tag() string {
+	if tag := a.settings.Load().Tag; tag != nil {
+		return tag.Tag
+	}
+
+	return WhatsAppGPTTag()
+}
+
+func (a *WhatsAppAccount) tagRegex() *regexp.Regexp {
+	if tag := a.settings.Load().Tag; tag != nil {
+		return tag.Regex
+	}
+
+	return tagConfig.Load().Regex
+}
+
+func (a *WhatsAppAccount) Persona() string {
+	return a.settings.Load().Persona
+}
+
+func (a *WhatsAppAccount) PII() pii.Config {
+	return a.settings.Load().PII
+}
+
+// IsAllowed reports whether the account answers the chat or the sender
+// of the message.
+func (a *WhatsAppAccount) IsAllowed(event *events.Message) bool {
+	allow := a.settings.Load().Allow
+	if len(allow) == 0 {
+		return true
+	}
+
+	for _, allowJID := range allow {
+		if allowJID == event.Info.Chat.ToNonAD() || allowJID == event.Info.Sender.ToNonAD() ||
+			(!event.Info.SenderAlt.IsEmpty() && allowJID == event.Info.SenderAlt.ToNonAD()) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (a *WhatsAppAccount) IsReady() bool {
+	return a.Client.IsConnected() && a.Client.IsLoggedIn()
+}
@@ -0,0 +1,106 @@
+package whatsapp
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/i18n"
+)
+
+// WhatsAppCodeAttachment is a long code block sent as a document.
+type WhatsAppCodeAttachment struct {
+	FileName string
+	MimeType string
+	Content  []byte
+}
+
+var codeBlockRegex = regexp.MustCompile("(?s)```([\\w+#.-]*)[ \\t]*\\n(.*?)```")
+
+var codeExtensions = map[string]string{
+	"bash":       "sh",
+	"c":          "c",
+	"c#":         "cs",
+	"c++":        "cpp",
+	"cpp":        "cpp",
+	"csharp":     "cs",
+	"cs":         "cs",
+	"css":        "css",
+	"dart":       "dart",
+	"dockerfile": "dockerfile",
+	"go":         "go",
+	"golang":     "go",
+	"html":       "html",
+	"java":       "java",
+	"javascript": "js",
+	"js":         "js",
+	"json":       "json",
+	"jsx":        "jsx",
+	"kotlin":     "kt",
+	"kt":         "kt",
+	"lua":        "lua",
+	"makefile":   "mk",
+	"markdown":   "md",
+	"md":         "md",
+	"php":        "php",
+	"powershell": "ps1",
+	"ps1":        "ps1",
+	"py":         "py",
+	"python":     "py",
+	"r":          "r",
+	"rb":         "rb",
+	"ruby":       "rb",
+	"rust":       "rs",
+	"rs":         "rs",
+	"scala":      "scala",
+	"sh":         "sh",
+	"shell":      "sh",
+	"sql":        "sql",
+	"swift":      "swift",
+	"toml":       "toml",
+	"ts":         "ts",
+	"tsx":        "tsx",
+	"typescript": "ts",
+	"xml":        "xml",
+	"yaml":       "yaml",
+	"yml":        "yaml",
+	"zsh":        "sh",
+}
+
+func WhatsAppCodeExtension(language string) string {
+	extension, isExist := codeExtensions[strings.ToLower(strings.TrimSpace(language))]
+	if !isExist {
+		return "txt"
+	}
+
+	return extension
+}
+
+func WhatsAppExtractCodeAttachment(response string, threshold int, language string) (string, []WhatsAppCodeAttachment) {
+	var attachments []WhatsAppCodeAttachment
+
+	// Skip Extraction if Threshold is not Valid
+	if threshold <= 0 {
+		return response, attachments
+	}
+
+	text := codeBlockRegex.ReplaceAllStringFunc(response, func(block string) string {
+		match := codeBlockRegex.FindStringSubmatch(block)
+
+		code := match[2]
+		if len(code) <= threshold {
+			return block
+		}
+
+		fileName := "code-" + strconv.Itoa(len(attachments)+1) + "." + WhatsAppCodeExtension(match[1])
+		attachments = append(attachments, WhatsAppCodeAttachment{
+			FileName: fileName,
+			MimeType: "text/plain",
+			Content:  []byte(code),
+		})
+
+		return i18n.Message(language, i18n.MessageCodeAttachment, fileName)
+	})
+
+	return text, attachments
+}
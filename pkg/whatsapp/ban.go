@@ -0,0 +1,180 @@
+package whatsapp
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+
+	pkgDatastore "github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/datastore"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/log"
+)
+
+func WhatsAppSenderJID(event *events.Message) string {
+	return event.Info.Sender.ToNonAD().String()
+}
+
+// WhatsAppParseJID accepts a full JID or a plain phone number.
+func WhatsAppParseJID(value string) (types.JID, error) {
+	value = strings.TrimSpace(value)
+
+	if strings.ContainsRune(value, '@') {
+		return types.ParseJID(value)
+	}
+
+	value = strings.TrimPrefix(value, "+")
+	if _, err := strconv.ParseUint(value, 10, 64); err != nil {
+		return types.EmptyJID, err
+	}
+
+	return types.NewJID(value, types.DefaultUserServer), nil
+}
+
+func WhatsAppIsAdmin(event *events.Message) bool {
+	for _, admin := range WhatsAppGPTAdmin {
+		if event.Info.Sender.User == admin || event.Info.SenderAlt.User == admin {
+			return true
+		}
+	}
+
+	return false
+}
+
+func WhatsAppIsBanned(event *events.Message) bool {
+	if WhatsAppGPTBanStrike <= 0 {
+		return false
+	}
+
+	ban, err := pkgDatastore.GetBan(context.Background(), WhatsAppSenderJID(event))
+	if err != nil {
+		log.WithError(err).WithFields(whatsAppLogFields(event)).Println(log.LogLevelWarn, "Failed to Get Sender Ban from Datastore")
+		return false
+	}
+
+	return ban.IsBanned()
+}
+
+// WhatsAppStrike counts a strike for the sender and bans the sender
+// temporarily when the strike threshold is reached. Each following ban
+// is doubling the ban duration, and the sender will also be blocked on
+// WhatsApp when the ban count reached the block threshold.
+func WhatsAppStrike(account *WhatsAppAccount, event *events.Message, reason string) {
+	if WhatsAppGPTBanStrike <= 0 {
+		return
+	}
+
+	ctx := context.Background()
+
+	ban, err := pkgDatastore.GetBan(ctx, WhatsAppSenderJID(event))
+	if err != nil {
+		log.WithError(err).WithFields(whatsAppLogFields(event)).Println(log.LogLevelWarn, "Failed to Get Sender Ban from Datastore")
+		return
+	}
+
+	// Reset Strikes When Last Strike is Outside Strike Window
+	if time.Since(ban.LastStrikeAt) > time.Duration(WhatsAppGPTBanStrikeWindow)*time.Hour {
+		ban.Strikes = 0
+	}
+
+	ban.Strikes++
+	ban.LastStrikeAt = time.Now()
+
+	if ban.Strikes >= WhatsAppGPTBanStrike {
+		ban.Strikes = 0
+		ban.BanCount++
+
+		multiplier := ban.BanCount - 1
+		if multiplier > 10 {
+			multiplier = 10
+		}
+
+		duration := time.Duration(WhatsAppGPTBanDuration) * time.Hour * time.Duration(1<<multiplier)
+		ban.BannedUntil = time.Now().Add(duration)
+
+		log.WithFields(whatsAppLogFields(event)).WithFields(log.Fields{"duration": duration.String(), "reason": reason}).Println(log.LogLevelWarn, "Banning Sender Due to Repeated Violation")
+		WhatsAppAudit(event, pkgDatastore.AuditReasonBan, "ban "+duration.String(), reason+" (ban #"+strconv.Itoa(ban.BanCount)+")")
+
+		if WhatsAppGPTBanBlock > 0 && ban.BanCount >= WhatsAppGPTBanBlock && !ban.IsBlocked {
+			_, err = account.Client.UpdateBlocklist(ctx, event.Info.Sender.ToNonAD(), events.BlocklistChangeActionBlock)
+			if err != nil {
+				log.WithError(err).WithFields(whatsAppLogFields(event)).Println(log.LogLevelError, "Failed to Block Sender on WhatsApp")
+			} else {
+				ban.IsBlocked = true
+
+				log.WithFields(whatsAppLogFields(event)).Println(log.LogLevelWarn, "Blocking Sender on WhatsApp")
+				WhatsAppAudit(event, pkgDatastore.AuditReasonBan, "block", reason+" (ban #"+strconv.Itoa(ban.BanCount)+")")
+			}
+		}
+	}
+
+	err = pkgDatastore.SaveBan(ctx, ban)
+	if err != nil {
+		log.WithError(err).WithFields(whatsAppLogFields(event)).Println(log.LogLevelWarn, "Failed to Save Sender Ban to Datastore")
+	}
+}
+
+// WhatsAppSenderJIDs returns the sender JID together with its phone number
+// or LID counterpart, since the sender is recorded with the JID used by
+// the message which can be either of them.
+func WhatsAppSenderJIDs(ctx context.Context, senderJID types.JID) []types.JID {
+	senderJID = senderJID.ToNonAD()
+	senderJIDs := []types.JID{senderJID}
+
+	if WhatsAppDatastore == nil {
+		return senderJIDs
+	}
+
+	var altJID types.JID
+	var err error
+
+	switch senderJID.Server {
+	case types.DefaultUserServer:
+		altJID, err = WhatsAppDatastore.LIDMap.GetLIDForPN(ctx, senderJID)
+	case types.HiddenUserServer:
+		altJID, err = WhatsAppDatastore.LIDMap.GetPNForLID(ctx, senderJID)
+	}
+
+	if err != nil {
+		log.WithError(err).WithFields(log.Fields{log.FieldSender: WhatsAppMaskJID(senderJID)}).Println(log.LogLevelWarn, "Failed to Resolve Sender LID Mapping")
+	} else if !altJID.IsEmpty() {
+		senderJIDs = append(senderJIDs, altJID.ToNonAD())
+	}
+
+	return senderJIDs
+}
+
+// WhatsAppUnban lifts the sender ban and also unblocks the sender
+// on WhatsApp for every running account when the sender was blocked.
+func WhatsAppUnban(senderJID types.JID) (bool, error) {
+	ctx := context.Background()
+
+	isLifted := false
+
+	for _, jid := range WhatsAppSenderJIDs(ctx, senderJID) {
+		ban, err := pkgDatastore.GetBan(ctx, jid.String())
+		if err != nil {
+			return false, err
+		}
+
+		if ban.IsBlocked {
+			for _, account := range WhatsAppAccounts() {
+				_, err = account.Client.UpdateBlocklist(ctx, jid, events.BlocklistChangeActionUnblock)
+				if err != nil {
+					return false, err
+				}
+			}
+		}
+
+		isDeleted, err := pkgDatastore.DeleteBan(ctx, jid.String())
+		if err != nil {
+			return false, err
+		}
+
+		isLifted = isLifted || isDeleted
+	}
+
+	return isLifted, nil
+}
@@ -0,0 +1,295 @@
+package whatsapp
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+
+	pkgDatastore "github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/datastore"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/filter"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/gpt"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/i18n"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/log"
+)
+
+func splitCommandArgument(command string) (string, string) {
+	command = strings.TrimSpace(command)
+
+	index := strings.IndexFunc(command, unicode.IsSpace)
+	if index < 0 {
+		return command, ""
+	}
+
+	return command[:index], strings.TrimSpace(command[index:])
+}
+
+func WhatsAppCommand(ctx context.Context, account *WhatsAppAccount, event *events.Message, command string, language string) bool {
+	name, argument := splitCommandArgument(command)
+	if len(name) == 0 {
+		return false
+	}
+
+	var response string
+
+	switch strings.ToLower(name) {
+	case "/lang":
+		response = whatsAppCommandLanguage(ctx, account, event, argument, language)
+	case "/translate":
+		response = whatsAppCommandTranslate(ctx, account, event, argument, language)
+	case "/unban":
+		response = whatsAppCommandUnban(account, event, argument, language)
+	case "/bans":
+		response = whatsAppCommandBans(event, language)
+	case "/reload":
+		response = whatsAppCommandReload(event, language)
+	case "/logout":
+		if !WhatsAppIsAdmin(event) {
+			response = i18n.Message(language, i18n.MessageAdminOnly)
+			break
+		}
+
+		// Reply Before Logging-out Since The Account Can not Send Afterwards
+		defer whatsAppLogoutAccount(account, event)
+		response = i18n.Message(language, i18n.MessageLogout)
+	default:
+		return false
+	}
+
+	_, err := WhatsAppSendGPTResponse(ctx, account, event, response)
+	if err != nil {
+		log.WithError(err).WithFields(whatsAppLogFields(event)).Println(log.LogLevelError, "Failed to Send Command Response")
+	}
+
+	return true
+}
+
+// whatsAppIsGroupAdmin reports whether the sender is a bot admin or an admin
+// of the group where the message is sent.
+func whatsAppIsGroupAdmin(ctx context.Context, account *WhatsAppAccount, event *events.Message) bool {
+	if WhatsAppIsAdmin(event) {
+		return true
+	}
+
+	groupInfo, err := account.Client.GetGroupInfo(ctx, event.Info.Chat)
+	if err != nil {
+		log.WithError(err).WithFields(whatsAppLogFields(event)).Println(log.LogLevelWarn, "Failed to Get WhatsApp Group Information")
+		return false
+	}
+
+	for _, participant := range groupInfo.Participants {
+		if !participant.IsAdmin && !participant.IsSuperAdmin {
+			continue
+		}
+
+		for _, participantJID := range []types.JID{participant.JID, participant.PhoneNumber, participant.LID} {
+			if len(participantJID.User) > 0 && (participantJID.User == event.Info.Sender.User || participantJID.User == event.Info.SenderAlt.User) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func whatsAppCommandLanguage(ctx context.Context, account *WhatsAppAccount, event *events.Message, argument string, language string) string {
+	if len(argument) == 0 {
+		return i18n.Message(language, i18n.MessageLanguageCurrent, language)
+	}
+
+	// Group Language is Shared by All Members, So Only Admins Can Change It
+	if event.Info.IsGroup && !whatsAppIsGroupAdmin(ctx, account, event) {
+		return i18n.Message(language, i18n.MessageAdminOnly)
+	}
+
+	newLanguage := strings.ToLower(argument)
+	if newLanguage == "default" {
+		err := pkgDatastore.DeleteChatLanguage(context.Background(), event.Info.Chat.String())
+		if err != nil {
+			log.WithError(err).WithFields(whatsAppLogFields(event)).Println(log.LogLevelError, "Failed to Reset Chat Language in Datastore")
+			return i18n.Message(language, i18n.MessageFailedResponse)
+		}
+
+		return i18n.Message(i18n.DefaultLanguage, i18n.MessageLanguageReset, i18n.DefaultLanguage)
+	}
+
+	if !i18n.IsSupported(newLanguage) {
+		return i18n.Message(language, i18n.MessageLanguageUnsupported, newLanguage, strings.Join(i18n.Languages(), ", "))
+	}
+
+	err := pkgDatastore.SetChatLanguage(context.Background(), event.Info.Chat.String(), newLanguage)
+	if err != nil {
+		log.WithError(err).WithFields(whatsAppLogFields(event)).Println(log.LogLevelError, "Failed to Set Chat Language in Datastore")
+		return i18n.Message(language, i18n.MessageFailedResponse)
+	}
+
+	return i18n.Message(newLanguage, i18n.MessageLanguageChanged, newLanguage)
+}
+
+func whatsAppCommandTranslate(ctx context.Context, account *WhatsAppAccount, event *events.Message, argument string, language string) string {
+	targetLanguage, text := splitCommandArgument(argument)
+
+	// Use Quoted Message as Text When No Text is Given
+	if len(text) == 0 {
+		text = strings.TrimSpace(WhatsAppQuotedMessageText(event.Message))
+	}
+
+	if len(targetLanguage) == 0 || len(text) == 0 {
+		return i18n.Message(language, i18n.MessageTranslateUsage, account.Tag(), account.Tag())
+	}
+
+	// Set Chat Presence
+	WhatsAppPresence(account, true)
+	WhatsAppComposeStatus(account, event.Info.Chat, true, false)
+	defer func() {
+		WhatsAppComposeStatus(account, event.Info.Chat, false, false)
+		WhatsAppPresence(account, false)
+	}()
+
+	if match, isBlocked := filter.Check(event.Info.Chat.String(), text); isBlocked {
+		log.WithFields(whatsAppLogFields(event)).WithFields(log.Fields{"term": match.Text, "mode": match.Mode, "source": match.Source}).Println(log.LogLevelWarn, "Translation is Blocked by Blocked Word")
+		WhatsAppAudit(event, pkgDatastore.AuditReasonBlockedWord, "refuse", match.Text+" ("+match.Source+")")
+		WhatsAppStrike(account, event, "Blocked Word")
+		return i18n.Message(language, i18n.MessageBlockedWord)
+	}
+
+	var warning string
+
+	moderation := WhatsAppModerate(ctx, event, text, "Question")
+	if moderation.Flagged {
+		switch moderation.Action {
+		case gpt.ModerationActionRefuse:
+			WhatsAppStrike(account, event, "Moderation Flag")
+			return i18n.Message(language, i18n.MessageModerationRefused)
+		case gpt.ModerationActionWarn:
+			warning = i18n.Message(language, i18n.MessageModerationWarning, strings.Join(moderation.Categories, ", "))
+		}
+	}
+
+	response, err := gpt.GPTTranslate(ctx, text, i18n.LanguageName(targetLanguage))
+
+	if err != nil || len(response) == 0 {
+		if err != nil {
+			log.WithError(err).WithFields(whatsAppLogFields(event)).Println(log.LogLevelError, "Failed to Get OpenAI GPT Translation")
+		}
+
+		return i18n.Message(language, i18n.MessageFailedResponse)
+	}
+
+	// Check Translation Like an Answer, So Blocked Text Can not be Translated Through
+	instructions := []string{gpt.GPTTranslateInstruction(i18n.LanguageName(targetLanguage))}
+
+	response, _, isRefused := WhatsAppFilterAnswer(ctx, event, language, text, instructions, response, "")
+	if isRefused {
+		return response
+	}
+
+	if moderation := WhatsAppModerate(ctx, event, response, "Answer"); moderation.Flagged {
+		switch moderation.Action {
+		case gpt.ModerationActionRefuse:
+			return i18n.Message(language, i18n.MessageModerationRefused)
+		case gpt.ModerationActionWarn:
+			warning = i18n.Message(language, i18n.MessageModerationWarning, strings.Join(moderation.Categories, ", "))
+		}
+	}
+
+	if len(warning) > 0 {
+		response = warning + "\n\n" + response
+	}
+
+	return response
+}
+
+func whatsAppCommandUnban(account *WhatsAppAccount, event *events.Message, argument string, language string) string {
+	if !WhatsAppIsAdmin(event) {
+		return i18n.Message(language, i18n.MessageAdminOnly)
+	}
+
+	senderJID, err := WhatsAppParseJID(argument)
+	if err != nil || len(argument) == 0 {
+		return i18n.Message(language, i18n.MessageUnbanUsage, account.Tag())
+	}
+
+	isLifted, err := WhatsAppUnban(senderJID)
+	if err != nil {
+		log.WithError(err).WithFields(whatsAppLogFields(event)).Println(log.LogLevelError, "Failed to Lift Sender Ban")
+		return i18n.Message(language, i18n.MessageFailedResponse)
+	}
+
+	if !isLifted {
+		return i18n.Message(language, i18n.MessageUnbanNotFound, senderJID.User)
+	}
+
+	log.WithFields(whatsAppLogFields(event)).WithFields(log.Fields{"banned_sender": WhatsAppMaskJID(senderJID)}).Println(log.LogLevelInfo, "Sender Ban is Lifted by Admin")
+	return i18n.Message(language, i18n.MessageUnbanSuccess, senderJID.User)
+}
+
+func whatsAppCommandBans(event *events.Message, language string) string {
+	if !WhatsAppIsAdmin(event) {
+		return i18n.Message(language, i18n.MessageAdminOnly)
+	}
+
+	bans, err := pkgDatastore.ListBan(context.Background(), true)
+	if err != nil {
+		log.WithError(err).WithFields(whatsAppLogFields(event)).Println(log.LogLevelError, "Failed to List Sender Ban")
+		return i18n.Message(language, i18n.MessageFailedResponse)
+	}
+
+	if len(bans) == 0 {
+		return i18n.Message(language, i18n.MessageBanListEmpty)
+	}
+
+	var lines []string
+	for _, ban := range bans {
+		senderJID, _ := types.ParseJID(ban.SenderJID)
+
+		line := "• " + senderJID.User + " - " + ban.BannedUntil.Format("2006-01-02 15:04")
+		if ban.IsBlocked {
+			line = line + " 🚫"
+		}
+
+		lines = append(lines, line)
+	}
+
+	return i18n.Message(language, i18n.MessageBanList) + "\n\n" + strings.Join(lines, "\n")
+}
+
+func whatsAppCommandReload(event *events.Message, language string) string {
+	if !WhatsAppIsAdmin(event) {
+		return i18n.Message(language, i18n.MessageAdminOnly)
+	}
+
+	changes, err := WhatsAppReloadConfig()
+	if err != nil {
+		log.WithError(err).WithFields(whatsAppLogFields(event)).Println(log.LogLevelError, "Failed to Reload Configuration by Admin")
+		return i18n.Message(language, i18n.MessageReloadFailed, err.Error())
+	}
+
+	if len(changes) == 0 {
+		return i18n.Message(language, i18n.MessageReloadNoChange)
+	}
+
+	var names []string
+	for _, change := range changes {
+		names = append(names, change.Name)
+	}
+
+	return i18n.Message(language, i18n.MessageReloadSuccess, strings.Join(names, ", "))
+}
+
+// whatsAppLogoutAccount logs-out the account through its running client and
+// lets the account supervisor stop it, so no second client is needed.
+func whatsAppLogoutAccount(account *WhatsAppAccount, event *events.Message) {
+	err := WhatsAppLogout(account.Client)
+	if err != nil {
+		log.WithError(err).WithFields(whatsAppLogFields(event)).WithFields(account.logFields()).Println(log.LogLevelError, "Failed to Logout WhatsApp Account by Admin")
+		return
+	}
+
+	log.WithFields(whatsAppLogFields(event)).WithFields(account.logFields()).Println(log.LogLevelInfo, "WhatsApp Account is Logged-out by Admin")
+
+	// Client Does not Emit Logged-out Event for Own Logout
+	account.supervisorHandler(&events.LoggedOut{Reason: events.ConnectFailureLoggedOut})
+}
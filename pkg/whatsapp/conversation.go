@@ -0,0 +1,46 @@
+package whatsapp
+
+import (
+	"context"
+	"time"
+
+	"go.mau.fi/whatsmeow/types/events"
+
+	pkgDatastore "github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/datastore"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/gpt"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/log"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/pii"
+)
+
+// whatsAppSaveConversation keeps the question and its answer in the
+// datastore to be exported as transcript. Personal data is redacted when
+// PII redaction is enabled for the account.
+func whatsAppSaveConversation(account *WhatsAppAccount, event *events.Message, question string, answer string, status string, model string, usage *gpt.GPTUsage) {
+	if !WhatsAppGPTTranscript {
+		return
+	}
+
+	if PIIConfig := account.PII(); PIIConfig.Enabled {
+		texts, _ := pii.RedactAll([]string{question, answer}, PIIConfig.Detectors)
+		question, answer = texts[0], texts[1]
+	}
+
+	err := pkgDatastore.InsertConversation(context.Background(), pkgDatastore.Conversation{
+		AccountJID:       account.JID.String(),
+		ChatJID:          event.Info.Chat.String(),
+		MessageID:        event.Info.ID,
+		SenderJID:        event.Info.Sender.String(),
+		Question:         question,
+		Answer:           answer,
+		Status:           status,
+		Model:            model,
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		SentAt:           event.Info.Timestamp,
+		AnsweredAt:       time.Now(),
+	})
+
+	if err != nil {
+		log.WithError(err).WithFields(whatsAppLogFields(event)).Println(log.LogLevelError, "Failed to Save Conversation to Datastore")
+	}
+}
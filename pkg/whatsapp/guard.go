@@ -0,0 +1,153 @@
+package whatsapp
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+
+	pkgDatastore "github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/datastore"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/log"
+)
+
+const (
+	StaleActionIgnore    string = "ignore"
+	StaleActionApologize string = "apologize"
+)
+
+const (
+	ForwardedActionAllow        string = "allow"
+	ForwardedActionIgnore       string = "ignore"
+	ForwardedActionDeprioritize string = "deprioritize"
+)
+
+// WhatsAppIsStale reports whether the message is older than the maximum
+// message age, which usually happens when offline messages are delivered
+// after reconnecting.
+func WhatsAppIsStale(event *events.Message) bool {
+	if WhatsAppGPTMaxAge <= 0 {
+		return false
+	}
+
+	return time.Since(event.Info.Timestamp) > time.Duration(WhatsAppGPTMaxAge)*time.Second
+}
+
+var processedPurgeMutex sync.Mutex
+var processedPurgeAt time.Time
+
+// Processed Message Claims are Owned by This Process Instance, Pending Claims
+// of Another Instance are Left by a Crashed Process and Can be Claimed Again
+var processInstance = strconv.FormatInt(time.Now().UnixNano(), 36)
+
+// WhatsAppIsProcessed reports whether the message was already processed
+// or is being processed, including by a previous connection or process,
+// and claims it as pending otherwise. The claim has to be completed after
+// the answer is sent or released when it is failed.
+func WhatsAppIsProcessed(event *events.Message) bool {
+	if WhatsAppGPTProcessedTTL <= 0 {
+		return false
+	}
+
+	ttl := time.Duration(WhatsAppGPTProcessedTTL) * time.Second
+
+	// Purge Expired Processed Message IDs at Most Once per TTL
+	processedPurgeMutex.Lock()
+	if time.Since(processedPurgeAt) > ttl {
+		processedPurgeAt = time.Now()
+
+		_, err := pkgDatastore.PurgeProcessedMessage(context.Background(), time.Now().Add(-ttl))
+		if err != nil {
+			log.WithError(err).Println(log.LogLevelWarn, "Failed to Purge Processed Message IDs from Datastore")
+		}
+	}
+	processedPurgeMutex.Unlock()
+
+	isClaimed, err := pkgDatastore.ClaimProcessedMessage(context.Background(), event.Info.Chat.String(), event.Info.ID, processInstance)
+	if err != nil {
+		// Datastore Failure Should Not Stop The Conversation
+		log.WithError(err).WithFields(whatsAppLogFields(event)).Println(log.LogLevelWarn, "Failed to Record Processed Message ID in Datastore")
+		return false
+	}
+
+	return !isClaimed
+}
+
+// whatsAppCompleteProcessed marks the claimed message as done, so it is
+// never answered again.
+func whatsAppCompleteProcessed(event *events.Message) {
+	if WhatsAppGPTProcessedTTL <= 0 {
+		return
+	}
+
+	err := pkgDatastore.CompleteProcessedMessage(context.Background(), event.Info.Chat.String(), event.Info.ID)
+	if err != nil {
+		log.WithError(err).WithFields(whatsAppLogFields(event)).Println(log.LogLevelWarn, "Failed to Complete Processed Message ID in Datastore")
+	}
+}
+
+// whatsAppReleaseProcessed removes the claim of the message which is not
+// answered, so it can be answered when it is delivered again.
+func whatsAppReleaseProcessed(event *events.Message) {
+	if WhatsAppGPTProcessedTTL <= 0 {
+		return
+	}
+
+	err := pkgDatastore.ReleaseProcessedMessage(context.Background(), event.Info.Chat.String(), event.Info.ID)
+	if err != nil {
+		log.WithError(err).WithFields(whatsAppLogFields(event)).Println(log.LogLevelWarn, "Failed to Release Processed Message ID in Datastore")
+	}
+}
+
+var floodMutex sync.Mutex
+var floodQuestions = make(map[string]time.Time)
+
+func whatsAppFloodKey(rjid types.JID, question string) string {
+	return rjid.String() + "|" + strings.Join(strings.Fields(strings.ToLower(question)), " ")
+}
+
+// WhatsAppIsFlood reports whether the same question was already asked
+// in the chat within the flood window, and remembers it otherwise.
+// The question has to be forgotten when it is not answered.
+func WhatsAppIsFlood(rjid types.JID, question string) bool {
+	if WhatsAppGPTFloodWindow <= 0 {
+		return false
+	}
+
+	window := time.Duration(WhatsAppGPTFloodWindow) * time.Second
+	key := whatsAppFloodKey(rjid, question)
+
+	floodMutex.Lock()
+	defer floodMutex.Unlock()
+
+	now := time.Now()
+
+	// Forget Expired Questions
+	for floodKey, askedAt := range floodQuestions {
+		if now.Sub(askedAt) > window {
+			delete(floodQuestions, floodKey)
+		}
+	}
+
+	if _, isExist := floodQuestions[key]; isExist {
+		return true
+	}
+
+	floodQuestions[key] = now
+	return false
+}
+
+// whatsAppForgetFlood removes the question which is not answered, so asking
+// it again is not ignored as a repeated question.
+func whatsAppForgetFlood(rjid types.JID, question string) {
+	if WhatsAppGPTFloodWindow <= 0 {
+		return
+	}
+
+	floodMutex.Lock()
+	delete(floodQuestions, whatsAppFloodKey(rjid, question))
+	floodMutex.Unlock()
+}
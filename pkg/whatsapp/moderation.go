@@ -0,0 +1,121 @@
+package whatsapp
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"go.mau.fi/whatsmeow/types/events"
+
+	pkgDatastore "github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/datastore"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/filter"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/gpt"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/i18n"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/log"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/tracing"
+)
+
+func WhatsAppAudit(event *events.Message, reason string, action string, detail string) {
+	err := pkgDatastore.InsertAudit(context.Background(), pkgDatastore.AuditEntry{
+		ChatJID:   event.Info.Chat.String(),
+		SenderJID: event.Info.Sender.ToNonAD().String(),
+		Reason:    reason,
+		Action:    action,
+		Detail:    detail,
+		CreatedAt: time.Now(),
+	})
+
+	if err != nil {
+		log.WithError(err).WithFields(whatsAppLogFields(event)).Println(log.LogLevelError, "Failed to Write Audit Entry to Datastore")
+	}
+}
+
+func WhatsAppModerate(ctx context.Context, event *events.Message, text string, subject string) gpt.ModerationResult {
+	ctx, span := tracing.StartSpan(ctx, "moderation", tracing.AttributeMessageID.String(event.Info.ID))
+
+	result, err := gpt.GPTModerate(ctx, text)
+	span.SetAttributes(tracing.AttributeFlagged.Bool(result.Flagged))
+	tracing.EndSpan(span, err)
+
+	if err != nil {
+		// Moderation Failure Should Not Stop The Conversation
+		log.WithError(err).WithFields(whatsAppLogFields(event)).Println(log.LogLevelWarn, "Failed to Moderate "+subject)
+		return gpt.ModerationResult{}
+	}
+
+	if result.Flagged {
+		log.WithFields(whatsAppLogFields(event)).WithFields(log.Fields{"categories": strings.Join(result.Categories, ", "), "action": result.Action}).Println(log.LogLevelWarn, subject+" is Flagged by Moderation")
+
+		reason := pkgDatastore.AuditReasonModeration
+		if subject != "Question" {
+			reason = pkgDatastore.AuditReasonModerationAnswer
+		}
+
+		WhatsAppAudit(event, reason, result.Action, strings.Join(result.Categories, ", "))
+	}
+
+	return result
+}
+
+func filterMatchText(matches []filter.Match) []string {
+	var terms []string
+	isExist := make(map[string]bool)
+
+	for _, match := range matches {
+		term := strings.ToLower(match.Text)
+		if !isExist[term] {
+			terms = append(terms, term)
+			isExist[term] = true
+		}
+	}
+
+	return terms
+}
+
+// WhatsAppFilterAnswer applies the blocked word filter to the model answer
+// and its reasoning using the configured answer action, and reports whether
+// the answer is replaced with a refusal.
+func WhatsAppFilterAnswer(ctx context.Context, event *events.Message, language string, question string, instructions []string, response string, reasoning string) (string, string, bool) {
+	if filter.BlockedWordAnswerAction == filter.AnswerActionOff {
+		return response, reasoning, false
+	}
+
+	chatJID := event.Info.Chat.String()
+
+	matches := filter.CheckAll(chatJID, response+"\n"+reasoning)
+	if len(matches) == 0 {
+		return response, reasoning, false
+	}
+
+	terms := filterMatchText(matches)
+	logEntry := log.WithFields(whatsAppLogFields(event)).WithFields(log.Fields{"terms": strings.Join(terms, ", ")})
+	WhatsAppAudit(event, pkgDatastore.AuditReasonBlockedWordAnswer, filter.BlockedWordAnswerAction, strings.Join(terms, ", "))
+
+	switch filter.BlockedWordAnswerAction {
+	case filter.AnswerActionRegenerate:
+		logEntry.Println(log.LogLevelWarn, "Answer is Containing Blocked Word, Regenerating Answer")
+
+		strictInstructions := append(append([]string{}, instructions...),
+			"Your answer must not contain any of the following words or anything related to them: "+strings.Join(terms, ", ")+". "+
+				"If the question can not be answered without them, politely refuse to answer.")
+
+		regenerateResponse, regenerateReasoning, err := gpt.GPTResponse(ctx, question, strictInstructions...)
+		if err == nil && len(regenerateResponse) > 0 && len(filter.CheckAll(chatJID, regenerateResponse+"\n"+regenerateReasoning)) == 0 {
+			logEntry.Println(log.LogLevelInfo, "Answer is Regenerated without Blocked Word")
+			return regenerateResponse, regenerateReasoning, false
+		}
+
+		logEntry.Println(log.LogLevelWarn, "Regenerated Answer is Still Not Acceptable, Replacing Answer with Refusal")
+		return i18n.Message(language, i18n.MessageBlockedWord), "", true
+	case filter.AnswerActionRefuse:
+		logEntry.Println(log.LogLevelWarn, "Answer is Containing Blocked Word, Replacing Answer with Refusal")
+		return i18n.Message(language, i18n.MessageBlockedWord), "", true
+	default:
+		logEntry.Println(log.LogLevelWarn, "Answer is Containing Blocked Word, Masking Matched Word")
+
+		response, _ = filter.Mask(chatJID, response)
+		reasoning, _ = filter.Mask(chatJID, reasoning)
+
+		return response, reasoning, false
+	}
+}
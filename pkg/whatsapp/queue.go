@@ -0,0 +1,288 @@
+package whatsapp
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mau.fi/whatsmeow/proto/waE2E"
+	"go.mau.fi/whatsmeow/types"
+	"go.mau.fi/whatsmeow/types/events"
+	"google.golang.org/protobuf/proto"
+
+	pkgDatastore "github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/datastore"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/log"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/metrics"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/tracing"
+)
+
+type queueItem struct {
+	Context       context.Context
+	Account       *WhatsAppAccount
+	Event         *events.Message
+	Question      string
+	IsLowPriority bool
+}
+
+var (
+	queueNormal chan queueItem
+	queueLow    chan queueItem
+	queueStop   chan struct{}
+)
+
+var (
+	queueMutex    sync.RWMutex
+	queueIsClosed bool
+	queueInFlight sync.WaitGroup
+)
+
+// Processing Context is Cancelled When In-Flight Questions
+// are not Finished within The Shutdown Timeout
+var processContext, processCancel = context.WithCancel(context.Background())
+
+func init() {
+	metrics.RegisterQueueDepth(WhatsAppQueueDepth)
+}
+
+func WhatsAppQueueStart(workers int) {
+	if queueNormal != nil {
+		return
+	}
+
+	queueNormal = make(chan queueItem, WhatsAppGPTQueueSize)
+	queueLow = make(chan queueItem, WhatsAppGPTQueueSize)
+	queueStop = make(chan struct{})
+
+	if workers <= 0 {
+		workers = 1
+	}
+
+	// Workers are Counted as In-Flight Until They Stop
+	queueInFlight.Add(workers)
+	for i := 0; i < workers; i++ {
+		go whatsAppQueueWorker()
+	}
+}
+
+func whatsAppQueueWorker() {
+	defer queueInFlight.Done()
+
+	for {
+		var item queueItem
+
+		// Always Prefer Normal Priority Questions
+		select {
+		case <-queueStop:
+			return
+		case item = <-queueNormal:
+		default:
+			select {
+			case <-queueStop:
+				return
+			case item = <-queueNormal:
+			case item = <-queueLow:
+			}
+		}
+
+		whatsAppProcessQueueItem(item)
+	}
+}
+
+func whatsAppProcessQueueItem(item queueItem) {
+	if !WhatsAppProcessQuestion(item.Context, item.Account, item.Event, item.Question) {
+		// Keep Cancelled Question to be Answered After Restarting
+		whatsAppSavePending(item)
+	}
+}
+
+func WhatsAppQueuePush(ctx context.Context, account *WhatsAppAccount, event *events.Message, question string, isLowPriority bool) {
+	item := queueItem{Context: ctx, Account: account, Event: event, Question: question, IsLowPriority: isLowPriority}
+
+	queueMutex.RLock()
+	isClosed, isStarted := queueIsClosed, queueNormal != nil
+
+	// Keep Question Received While Shutting Down
+	if isClosed {
+		queueMutex.RUnlock()
+		whatsAppSavePending(item)
+		return
+	}
+
+	// Process Directly When Queue is not Started
+	if !isStarted {
+		queueInFlight.Add(1)
+		queueMutex.RUnlock()
+
+		whatsAppProcessQueueItem(item)
+		queueInFlight.Done()
+		return
+	}
+
+	queue := queueNormal
+	if isLowPriority {
+		queue = queueLow
+	}
+
+	select {
+	case queue <- item:
+	default:
+		log.WithFields(whatsAppLogFields(event)).Println(log.LogLevelWarn, "WhatsApp GPT Queue is Full, Dropping Question")
+		WhatsAppAudit(event, pkgDatastore.AuditReasonRateLimit, "drop", "queue full")
+		whatsAppReleaseProcessed(event)
+		whatsAppForgetFlood(event.Info.Chat, question)
+		metrics.QuestionBlocked.WithLabelValues(WhatsAppChatType(event.Info.Chat), "queue_full").Inc()
+	}
+
+	queueMutex.RUnlock()
+}
+
+func WhatsAppQueueDepth() int {
+	if queueNormal == nil {
+		return 0
+	}
+
+	return len(queueNormal) + len(queueLow)
+}
+
+func whatsAppWaitInFlight(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		queueInFlight.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// WhatsAppQueueStop stops accepting new questions and waits for in-flight
+// questions up to the timeout. Questions which are still queued or are
+// cancelled after the timeout are saved to the datastore.
+func WhatsAppQueueStop(timeout time.Duration) {
+	queueMutex.Lock()
+	queueIsClosed = true
+	queueMutex.Unlock()
+
+	if queueStop != nil {
+		close(queueStop)
+	}
+
+	log.WithFields(log.Fields{"timeout": timeout.String()}).Println(log.LogLevelInfo, "Waiting for In-Flight Questions to be Answered")
+
+	if !whatsAppWaitInFlight(timeout) {
+		log.Println(log.LogLevelWarn, "In-Flight Questions are not Answered within Shutdown Timeout, Cancelling Them")
+
+		processCancel()
+		whatsAppWaitInFlight(5 * time.Second)
+	}
+
+	// Flush Queued Questions to Datastore
+	count := 0
+	for queueNormal != nil {
+		select {
+		case item := <-queueNormal:
+			whatsAppSavePending(item)
+			count++
+			continue
+		case item := <-queueLow:
+			whatsAppSavePending(item)
+			count++
+			continue
+		default:
+		}
+
+		break
+	}
+
+	if count > 0 {
+		log.WithFields(log.Fields{"count": count}).Println(log.LogLevelInfo, "Saved Queued Questions to Datastore")
+	}
+}
+
+func whatsAppSavePending(item queueItem) {
+	err := pkgDatastore.SavePendingQuestion(context.Background(), pkgDatastore.PendingQuestion{
+		AccountJID:    item.Account.JID.String(),
+		ChatJID:       item.Event.Info.Chat.String(),
+		MessageID:     item.Event.Info.ID,
+		SenderJID:     item.Event.Info.Sender.String(),
+		Question:      item.Question,
+		IsLowPriority: item.IsLowPriority,
+		SentAt:        item.Event.Info.Timestamp,
+		CreatedAt:     time.Now(),
+	})
+
+	if err != nil {
+		log.WithError(err).WithFields(whatsAppLogFields(item.Event)).Println(log.LogLevelError, "Failed to Save Pending Question to Datastore")
+	}
+}
+
+var (
+	restoredAccounts      = make(map[string]bool)
+	restoredAccountsMutex sync.Mutex
+)
+
+// WhatsAppQueueRestore pushes the account questions saved on the previous
+// shutdown back to the queue. Questions are only restored once per process
+// start and are checked again for banned senders and duplicate answers.
+func WhatsAppQueueRestore(account *WhatsAppAccount) {
+	restoredAccountsMutex.Lock()
+	defer restoredAccountsMutex.Unlock()
+
+	if restoredAccounts[account.JID.String()] {
+		return
+	}
+
+	pendings, err := pkgDatastore.TakePendingQuestion(context.Background(), account.JID.String())
+	if err != nil {
+		log.WithError(err).WithFields(account.logFields()).Println(log.LogLevelError, "Failed to Load Pending Questions from Datastore")
+		return
+	}
+
+	restoredAccounts[account.JID.String()] = true
+
+	for _, pending := range pendings {
+		chatJID, err := types.ParseJID(pending.ChatJID)
+		if err != nil {
+			continue
+		}
+
+		senderJID, err := types.ParseJID(pending.SenderJID)
+		if err != nil {
+			continue
+		}
+
+		event := &events.Message{
+			Info: types.MessageInfo{
+				MessageSource: types.MessageSource{
+					Chat:    chatJID,
+					Sender:  senderJID,
+					IsGroup: chatJID.Server == types.GroupServer,
+				},
+				ID:        pending.MessageID,
+				Timestamp: pending.SentAt,
+			},
+			Message: &waE2E.Message{
+				Conversation: proto.String(pending.Question),
+			},
+		}
+
+		ctx, span := tracing.StartSpan(context.Background(), "whatsapp.question.restore",
+			tracing.AttributeMessageID.String(event.Info.ID), tracing.AttributeChatType.String(WhatsAppChatType(chatJID)))
+
+		// Restored Questions are Already Guarded When Received, So Skip The Stale
+		// and Flood Checks Since They Would Reject Questions Kept Over Restart
+		if whatsAppGuardClaim(event, span) {
+			WhatsAppQueuePush(ctx, account, event, pending.Question, pending.IsLowPriority)
+		}
+
+		span.End()
+	}
+
+	if len(pendings) > 0 {
+		log.WithFields(account.logFields()).WithFields(log.Fields{"count": len(pendings)}).Println(log.LogLevelInfo, "Restored Pending Questions from Datastore")
+	}
+}
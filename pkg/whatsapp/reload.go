@@ -0,0 +1,103 @@
+package whatsapp
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/env"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/gpt"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/log"
+)
+
+// whatsAppTagConfig is the tag used to ask the bot, kept together with its
+// compiled pattern so both are swapped at once when reloading.
+type whatsAppTagConfig struct {
+	Tag   string
+	Regex *regexp.Regexp
+}
+
+var tagConfig atomic.Pointer[whatsAppTagConfig]
+
+var reloadMutex sync.Mutex
+
+func WhatsAppGPTTag() string {
+	return tagConfig.Load().Tag
+}
+
+func whatsAppLoadTag() (*whatsAppTagConfig, error) {
+	tag, err := env.GetEnvString("WHATSAPP_GPT_TAG")
+	if err != nil {
+		return nil, err
+	}
+
+	tag = strings.TrimSpace(strings.ToLower(tag))
+
+	regex, err := regexp.Compile("\\b(?i)(" + tag + " " + ")")
+	if err != nil {
+		return nil, errors.New("Environment Variable 'WHATSAPP_GPT_TAG' is Not a Valid Pattern: " + err.Error())
+	}
+
+	return &whatsAppTagConfig{Tag: tag, Regex: regex}, nil
+}
+
+func (c *whatsAppTagConfig) Settings() map[string]string {
+	return map[string]string{
+		"WHATSAPP_GPT_TAG": c.Tag,
+	}
+}
+
+// WhatsAppReloadConfig reads the configuration again and swaps the tag,
+// model and account settings without touching the WhatsApp connections.
+// Nothing is applied when any of the settings is invalid.
+func WhatsAppReloadConfig() ([]env.Change, error) {
+	reloadMutex.Lock()
+	defer reloadMutex.Unlock()
+
+	err := env.Reload()
+	if err != nil {
+		return nil, err
+	}
+
+	newModel, err := gpt.GPTLoadModel()
+	if err != nil {
+		return nil, err
+	}
+
+	newTag, err := whatsAppLoadTag()
+	if err != nil {
+		return nil, err
+	}
+
+	changes := append(env.Diff(gpt.GPTModel().Settings(), newModel.Settings()),
+		env.Diff(tagConfig.Load().Settings(), newTag.Settings())...)
+
+	runningAccounts := WhatsAppAccounts()
+	newAccountSettings := make([]*whatsAppAccountSettings, len(runningAccounts))
+
+	for i, account := range runningAccounts {
+		newAccountSettings[i], err = whatsAppLoadAccountSettings(account.JID)
+		if err != nil {
+			return nil, errors.New("WhatsApp Account " + WhatsAppMaskJID(account.JID) + " Settings are Invalid: " + err.Error())
+		}
+
+		changes = append(changes, env.Diff(account.settings.Load().Settings(account), newAccountSettings[i].Settings(account))...)
+	}
+
+	gpt.GPTSetModel(newModel)
+	tagConfig.Store(newTag)
+
+	for i, account := range runningAccounts {
+		account.settings.Store(newAccountSettings[i])
+	}
+
+	for _, change := range changes {
+		log.WithFields(log.Fields{"setting": change.Name, "old": change.Old, "new": change.New}).Println(log.LogLevelInfo, "Configuration Setting Changed")
+	}
+
+	log.WithFields(log.Fields{"changes": len(changes)}).Println(log.LogLevelInfo, "Configuration Reloaded")
+
+	return changes, nil
+}
@@ -0,0 +1,281 @@
+package whatsapp
+
+import (
+	"context"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"go.mau.fi/whatsmeow"
+	"go.mau.fi/whatsmeow/types/events"
+
+	pkgDatastore "github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/datastore"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/log"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/metrics"
+)
+
+const (
+	ConnectionStateWaitingLogin   string = "waiting_login"
+	ConnectionStateConnecting     string = "connecting"
+	ConnectionStateConnected      string = "connected"
+	ConnectionStateDisconnected   string = "disconnected"
+	ConnectionStateLoggedOut      string = "logged_out"
+	ConnectionStateStreamReplaced string = "stream_replaced"
+	ConnectionStateTemporaryBan   string = "temporary_ban"
+)
+
+type connectionEvent struct {
+	State  string
+	Detail string
+	Delay  time.Duration
+}
+
+func (a *WhatsAppAccount) ConnectionState() string {
+	a.connectionStateMutex.RLock()
+	defer a.connectionStateMutex.RUnlock()
+
+	return a.connectionState
+}
+
+func (a *WhatsAppAccount) setConnectionState(state string, detail string) {
+	a.connectionStateMutex.Lock()
+	previousState := a.connectionState
+	a.connectionState = state
+	a.connectionStateMutex.Unlock()
+
+	if previousState == state {
+		return
+	}
+
+	level := log.LogLevelInfo
+	if state != ConnectionStateConnected && state != ConnectionStateConnecting {
+		level = log.LogLevelWarn
+	}
+
+	log.WithFields(a.logFields()).WithFields(log.Fields{"from": previousState, "to": state, "detail": detail}).Println(level, "WhatsApp Client Connection State Changed")
+}
+
+// whatsAppBackoff returns the exponential reconnect delay for the given
+// attempt with a random jitter between half and the full delay, so
+// several instances are not reconnecting at the same time.
+func whatsAppBackoff(attempt int) time.Duration {
+	delay := time.Duration(WhatsAppClientReconnectMin) * time.Second
+	maxDelay := time.Duration(WhatsAppClientReconnectMax) * time.Second
+
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay = delay * 2
+	}
+
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+
+	if delay <= 0 {
+		return 0
+	}
+
+	return delay/2 + rand.N(delay/2+1)
+}
+
+func (a *WhatsAppAccount) supervisorHandler(event interface{}) {
+	var connEvent connectionEvent
+
+	switch evt := event.(type) {
+	case *events.Connected:
+		connEvent = connectionEvent{State: ConnectionStateConnected}
+	case *events.Disconnected:
+		connEvent = connectionEvent{State: ConnectionStateDisconnected, Detail: "Connection Closed"}
+	case *events.ConnectFailure:
+		connEvent = connectionEvent{State: ConnectionStateDisconnected, Detail: "Connect Failure " + evt.Reason.String()}
+	case *events.KeepAliveTimeout:
+		log.WithFields(a.logFields()).WithFields(log.Fields{"error_count": evt.ErrorCount}).Println(log.LogLevelWarn, "WhatsApp Client Keep-Alive Timeout")
+
+		// Force Reconnection When Keep-Alive Keeps Failing
+		if time.Since(evt.LastSuccess) > whatsmeow.KeepAliveMaxFailTime {
+			connEvent = connectionEvent{State: ConnectionStateDisconnected, Detail: "Keep-Alive Timeout"}
+		} else {
+			return
+		}
+	case *events.KeepAliveRestored:
+		log.WithFields(a.logFields()).Println(log.LogLevelInfo, "WhatsApp Client Keep-Alive Restored")
+		return
+	case *events.LoggedOut:
+		connEvent = connectionEvent{State: ConnectionStateLoggedOut, Detail: evt.Reason.String()}
+	case *events.StreamReplaced:
+		connEvent = connectionEvent{State: ConnectionStateStreamReplaced, Detail: "Session is Opened from Another Instance"}
+	case *events.TemporaryBan:
+		connEvent = connectionEvent{State: ConnectionStateTemporaryBan, Detail: evt.String(), Delay: evt.Expire}
+	default:
+		return
+	}
+
+	select {
+	case a.connectionEvents <- connEvent:
+	default:
+		log.WithFields(a.logFields()).WithFields(log.Fields{"state": connEvent.State}).Println(log.LogLevelWarn, "WhatsApp Client Connection Event is Dropped")
+	}
+}
+
+// Daemon Heartbeat is Sent on Every Supervisor Check
+const whatsAppHeartbeatInterval = 5 * time.Second
+
+// WhatsAppDaemonIsRunning reports whether a daemon is holding the WhatsApp
+// sessions of the datastore, based on its recent heartbeat.
+func WhatsAppDaemonIsRunning() (bool, error) {
+	return pkgDatastore.IsDaemonRunning(context.Background(), time.Now().Add(-3*whatsAppHeartbeatInterval))
+}
+
+func whatsAppHeartbeat() {
+	err := pkgDatastore.SaveDaemonHeartbeat(context.Background(), processInstance)
+	if err != nil {
+		log.WithError(err).Println(log.LogLevelWarn, "Failed to Save Daemon Heartbeat to Datastore")
+	}
+}
+
+func whatsAppWait(stop <-chan struct{}, delay time.Duration) bool {
+	select {
+	case <-stop:
+		return false
+	case <-time.After(delay):
+		return true
+	}
+}
+
+// WhatsAppSupervise starts a client for every logged-in device in the
+// datastore and keeps them connected until the stop channel is closed.
+// Devices logged-in while running are started on the next check.
+func WhatsAppSupervise(stop <-chan struct{}) {
+	var supervisors sync.WaitGroup
+	isWaitingLogin := false
+
+	for {
+		whatsAppHeartbeat()
+
+		devices, err := WhatsAppDatastore.GetAllDevices(context.Background())
+		if err != nil {
+			log.WithError(err).Println(log.LogLevelError, "Failed to Load WhatsApp Client Devices from Datastore")
+		}
+
+		for _, device := range devices {
+			if device.ID == nil || whatsAppAccountExists(device.ID.ToNonAD()) {
+				continue
+			}
+
+			account := whatsAppNewAccount(device)
+			whatsAppAddAccount(account)
+
+			log.WithFields(account.logFields()).Println(log.LogLevelInfo, "Starting WhatsApp Client Event Listener for OpenAI GPT")
+
+			supervisors.Add(1)
+			go func() {
+				defer supervisors.Done()
+				whatsAppSuperviseAccount(account, stop)
+			}()
+		}
+
+		if len(WhatsAppAccounts()) == 0 {
+			if !isWaitingLogin {
+				log.Println(log.LogLevelWarn, "No Logged-in Device in Datastore, Waiting for WhatsApp Client Login")
+			}
+
+			isWaitingLogin = true
+		} else {
+			isWaitingLogin = false
+		}
+
+		if !whatsAppWait(stop, whatsAppHeartbeatInterval) {
+			supervisors.Wait()
+
+			err = pkgDatastore.DeleteDaemonHeartbeat(context.Background(), processInstance)
+			if err != nil {
+				log.WithError(err).Println(log.LogLevelWarn, "Failed to Delete Daemon Heartbeat from Datastore")
+			}
+
+			return
+		}
+	}
+}
+
+// whatsAppSuperviseAccount keeps the account connected until the stop
+// channel is closed or the account is logged-out. It reacts to the client
+// connection events and reconnects using exponential backoff instead of
+// polling the connection status.
+func whatsAppSuperviseAccount(account *WhatsAppAccount, stop <-chan struct{}) {
+	attempt := 0
+
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		if !account.Client.IsConnected() {
+			account.setConnectionState(ConnectionStateConnecting, "")
+
+			err := account.Client.Connect()
+			if err != nil {
+				attempt++
+				delay := whatsAppBackoff(attempt)
+
+				account.setConnectionState(ConnectionStateDisconnected, err.Error())
+				log.WithFields(account.logFields()).WithFields(log.Fields{"attempt": attempt, "delay": delay.String()}).Println(log.LogLevelWarn, "Waiting to Reconnect WhatsApp Client")
+
+				if !whatsAppWait(stop, delay) {
+					return
+				}
+
+				metrics.Reconnect.Inc()
+				continue
+			}
+		}
+
+		var connEvent connectionEvent
+
+		select {
+		case <-stop:
+			return
+		case connEvent = <-account.connectionEvents:
+		}
+
+		account.setConnectionState(connEvent.State, connEvent.Detail)
+
+		switch connEvent.State {
+		case ConnectionStateConnected:
+			attempt = 0
+
+			// Answer Questions Left from Previous Shutdown
+			WhatsAppQueueRestore(account)
+			continue
+		case ConnectionStateLoggedOut:
+			// Device is Removed from Datastore, Wait for The Device to be Logged-in Again
+			account.Client.RemoveEventHandlers()
+			account.Client.Disconnect()
+			whatsAppRemoveAccount(account)
+			return
+		}
+
+		account.Client.Disconnect()
+
+		attempt++
+		delay := whatsAppBackoff(attempt)
+
+		switch connEvent.State {
+		case ConnectionStateTemporaryBan:
+			if connEvent.Delay > delay {
+				delay = connEvent.Delay
+			}
+		case ConnectionStateStreamReplaced:
+			// Avoid Fighting Over The Session with Another Instance
+			delay = time.Duration(WhatsAppClientReconnectMax) * time.Second
+		}
+
+		log.WithFields(account.logFields()).WithFields(log.Fields{"attempt": attempt, "delay": delay.String()}).Println(log.LogLevelWarn, "Waiting to Reconnect WhatsApp Client")
+
+		if !whatsAppWait(stop, delay) {
+			return
+		}
+
+		metrics.Reconnect.Inc()
+	}
+}
@@ -3,10 +3,13 @@ package whatsapp
 import (
 	"context"
 	"errors"
-	"regexp"
+	"net"
 	"runtime"
+	"strconv"
 	"strings"
+	"time"
 
+	oteltrace "go.opentelemetry.io/otel/trace"
 	"google.golang.org/protobuf/proto"
 
 	"go.mau.fi/whatsmeow"
@@ -18,95 +21,275 @@ import (
 	"go.mau.fi/whatsmeow/types"
 	"go.mau.fi/whatsmeow/types/events"
 
+	pkgDatastore "github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/datastore"
 	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/env"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/filter"
 	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/gpt"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/i18n"
 	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/log"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/metrics"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/pii"
+	"github.com/dimaskiddo/go-whatsapp-multidevice-gpt/pkg/tracing"
 )
 
 var WhatsAppDatastore *sqlstore.Container
-var WhatsAppClient *whatsmeow.Client
+
+var WhatsAppClientProxyURL string
+
+var (
+	WhatsAppClientReconnectMin,
+	WhatsAppClientReconnectMax int
+)
+
+var (
+	WhatsAppGPTReaction bool
+	WhatsAppGPTReactionReceived,
+	WhatsAppGPTReactionSuccess,
+	WhatsAppGPTReactionFailure string
+)
 
 var (
-	WhatsAppClientProxyURL,
-	WhatsAppGPTTag string
+	WhatsAppGPTCodeAttachment     bool
+	WhatsAppGPTCodeAttachmentSize int
 )
 
-var WhatsAppGPTTagRegex *regexp.Regexp
+var WhatsAppGPTLanguageDetect bool
+
+var WhatsAppGPTTranscript bool
+
+var (
+	WhatsAppGPTForwardedAction,
+	WhatsAppGPTStaleAction string
+	WhatsAppGPTMaxAge,
+	WhatsAppGPTForwardedScore,
+	WhatsAppGPTFloodWindow,
+	WhatsAppGPTProcessedTTL,
+	WhatsAppGPTQueueSize,
+	WhatsAppGPTQueueWorker,
+	WhatsAppGPTShutdownTimeout,
+	WhatsAppGPTSendRetry int
+)
+
+var (
+	WhatsAppGPTAdmin []string
+	WhatsAppGPTBanStrike,
+	WhatsAppGPTBanStrikeWindow,
+	WhatsAppGPTBanDuration,
+	WhatsAppGPTBanBlock int
+)
 
 func init() {
 	var err error
 
-	dbType, err := env.GetEnvString("WHATSAPP_DATASTORE_TYPE")
+	datastore := sqlstore.NewWithDB(pkgDatastore.DB, pkgDatastore.DBType, log.WhatsAppLogger(log.ComponentDatastore))
+
+	err = datastore.Upgrade(context.Background())
 	if err != nil {
-		log.Println(log.LogLevelFatal, "Error Parse Environment Variable for WhatsApp Client Datastore Type")
+		log.WithError(err).Println(log.LogLevelFatal, "Error Connect WhatsApp Client Datastore")
 	}
 
-	dbURI, err := env.GetEnvString("WHATSAPP_DATASTORE_URI")
+	WhatsAppClientProxyURL, _ = env.GetEnvString("WHATSAPP_CLIENT_PROXY_URL")
+
+	WhatsAppClientReconnectMin, err = env.GetEnvInt("WHATSAPP_CLIENT_RECONNECT_MIN")
 	if err != nil {
-		log.Println(log.LogLevelFatal, "Error Parse Environment Variable for WhatsApp Client Datastore URI")
+		WhatsAppClientReconnectMin = 2
 	}
 
-	datastore, err := sqlstore.New(context.Background(), dbType, dbURI, nil)
+	WhatsAppClientReconnectMax, err = env.GetEnvInt("WHATSAPP_CLIENT_RECONNECT_MAX")
 	if err != nil {
-		log.Println(log.LogLevelFatal, "Error Connect WhatsApp Client Datastore")
+		WhatsAppClientReconnectMax = 300
 	}
 
-	WhatsAppClientProxyURL, _ = env.GetEnvString("WHATSAPP_CLIENT_PROXY_URL")
+	tag, err := whatsAppLoadTag()
+	if err != nil {
+		log.WithError(err).Println(log.LogLevelFatal, "Error Parse Environment Variable for WhatsApp GPT Tag")
+	}
+
+	tagConfig.Store(tag)
+
+	WhatsAppGPTReaction, err = env.GetEnvBool("WHATSAPP_GPT_REACTION")
+	if err != nil {
+		WhatsAppGPTReaction = false
+	}
+
+	WhatsAppGPTReactionReceived, err = env.GetEnvString("WHATSAPP_GPT_REACTION_RECEIVED")
+	if err != nil {
+		WhatsAppGPTReactionReceived = "⏳"
+	}
+
+	WhatsAppGPTReactionSuccess, err = env.GetEnvString("WHATSAPP_GPT_REACTION_SUCCESS")
+	if err != nil {
+		WhatsAppGPTReactionSuccess = "✅"
+	}
+
+	WhatsAppGPTReactionFailure, err = env.GetEnvString("WHATSAPP_GPT_REACTION_FAILURE")
+	if err != nil {
+		WhatsAppGPTReactionFailure = "❌"
+	}
+
+	WhatsAppGPTCodeAttachment, err = env.GetEnvBool("WHATSAPP_GPT_CODE_ATTACHMENT")
+	if err != nil {
+		WhatsAppGPTCodeAttachment = false
+	}
+
+	WhatsAppGPTCodeAttachmentSize, err = env.GetEnvInt("WHATSAPP_GPT_CODE_ATTACHMENT_SIZE")
+	if err != nil {
+		WhatsAppGPTCodeAttachmentSize = 1024
+	}
+
+	WhatsAppGPTLanguageDetect, err = env.GetEnvBool("WHATSAPP_GPT_LANGUAGE_DETECT")
+	if err != nil {
+		WhatsAppGPTLanguageDetect = true
+	}
+
+	WhatsAppGPTTranscript, err = env.GetEnvBool("WHATSAPP_GPT_TRANSCRIPT")
+	if err != nil {
+		WhatsAppGPTTranscript = true
+	}
+
+	WhatsAppGPTMaxAge, err = env.GetEnvInt("WHATSAPP_GPT_MAX_AGE")
+	if err != nil {
+		WhatsAppGPTMaxAge = 300
+	}
+
+	WhatsAppGPTStaleAction, err = env.GetEnvString("WHATSAPP_GPT_STALE_ACTION")
+	if err != nil {
+		WhatsAppGPTStaleAction = StaleActionIgnore
+	}
+
+	WhatsAppGPTStaleAction = strings.ToLower(WhatsAppGPTStaleAction)
+	switch WhatsAppGPTStaleAction {
+	case StaleActionIgnore, StaleActionApologize:
+	default:
+		log.Println(log.LogLevelWarn, "Unknown WhatsApp GPT Stale Action '"+WhatsAppGPTStaleAction+"', Fallback to '"+StaleActionIgnore+"'")
+		WhatsAppGPTStaleAction = StaleActionIgnore
+	}
+
+	WhatsAppGPTForwardedAction, err = env.GetEnvString("WHATSAPP_GPT_FORWARDED_ACTION")
+	if err != nil {
+		WhatsAppGPTForwardedAction = ForwardedActionIgnore
+	}
+
+	WhatsAppGPTForwardedAction = strings.ToLower(WhatsAppGPTForwardedAction)
+	switch WhatsAppGPTForwardedAction {
+	case ForwardedActionAllow, ForwardedActionIgnore, ForwardedActionDeprioritize:
+	default:
+		log.Println(log.LogLevelWarn, "Unknown WhatsApp GPT Forwarded Action '"+WhatsAppGPTForwardedAction+"', Fallback to '"+ForwardedActionIgnore+"'")
+		WhatsAppGPTForwardedAction = ForwardedActionIgnore
+	}
+
+	WhatsAppGPTForwardedScore, err = env.GetEnvInt("WHATSAPP_GPT_FORWARDED_SCORE")
+	if err != nil {
+		WhatsAppGPTForwardedScore = 5
+	}
+
+	WhatsAppGPTFloodWindow, err = env.GetEnvInt("WHATSAPP_GPT_FLOOD_WINDOW")
+	if err != nil {
+		WhatsAppGPTFloodWindow = 60
+	}
+
+	WhatsAppGPTProcessedTTL, err = env.GetEnvInt("WHATSAPP_GPT_PROCESSED_TTL")
+	if err != nil {
+		WhatsAppGPTProcessedTTL = 86400
+	}
+
+	WhatsAppGPTQueueSize, err = env.GetEnvInt("WHATSAPP_GPT_QUEUE_SIZE")
+	if err != nil {
+		WhatsAppGPTQueueSize = 100
+	}
+
+	WhatsAppGPTQueueWorker, err = env.GetEnvInt("WHATSAPP_GPT_QUEUE_WORKER")
+	if err != nil {
+		WhatsAppGPTQueueWorker = 1
+	}
 
-	WhatsAppGPTTag, err = env.GetEnvString("WHATSAPP_GPT_TAG")
+	WhatsAppGPTShutdownTimeout, err = env.GetEnvInt("WHATSAPP_GPT_SHUTDOWN_TIMEOUT")
 	if err != nil {
-		log.Println(log.LogLevelFatal, "Error Parse Environment Variable for WhatsApp GPT Tag")
+		WhatsAppGPTShutdownTimeout = 30
 	}
 
-	WhatsAppGPTTag = strings.TrimSpace(strings.ToLower(WhatsAppGPTTag))
-	WhatsAppGPTTagRegex = regexp.MustCompile("\\b(?i)(" + WhatsAppGPTTag + " " + ")")
+	WhatsAppGPTSendRetry, err = env.GetEnvInt("WHATSAPP_GPT_SEND_RETRY")
+	if err != nil {
+		WhatsAppGPTSendRetry = 3
+	}
+
+	admins, err := env.GetEnvString("WHATSAPP_GPT_ADMIN")
+	if err == nil {
+		for _, admin := range strings.Split(admins, ",") {
+			if admin = strings.TrimPrefix(strings.TrimSpace(admin), "+"); len(admin) > 0 {
+				WhatsAppGPTAdmin = append(WhatsAppGPTAdmin, admin)
+			}
+		}
+	}
+
+	WhatsAppGPTBanStrike, err = env.GetEnvInt("WHATSAPP_GPT_BAN_STRIKE")
+	if err != nil {
+		WhatsAppGPTBanStrike = 3
+	}
+
+	WhatsAppGPTBanStrikeWindow, err = env.GetEnvInt("WHATSAPP_GPT_BAN_STRIKE_WINDOW")
+	if err != nil {
+		WhatsAppGPTBanStrikeWindow = 24
+	}
+
+	WhatsAppGPTBanDuration, err = env.GetEnvInt("WHATSAPP_GPT_BAN_DURATION")
+	if err != nil {
+		WhatsAppGPTBanDuration = 1
+	}
+
+	WhatsAppGPTBanBlock, err = env.GetEnvInt("WHATSAPP_GPT_BAN_BLOCK")
+	if err != nil {
+		WhatsAppGPTBanBlock = 0
+	}
 
 	WhatsAppDatastore = datastore
 }
 
-func WhatsAppInitClient(device *store.Device) {
+// WhatsAppNewClient creates the client for the stored device, or for a new
+// device when the device is nil to be paired with WhatsAppLogin.
+func WhatsAppNewClient(device *store.Device) *whatsmeow.Client {
 	var err error
 	wabin.IndentXML = true
 
-	if WhatsAppClient == nil {
-		if device == nil {
-			// Initialize New WhatsApp Client Device in Datastore
-			device = WhatsAppDatastore.NewDevice()
-		}
+	if device == nil {
+		// Initialize New WhatsApp Client Device in Datastore
+		device = WhatsAppDatastore.NewDevice()
+	}
 
-		// Set Client Properties
-		store.DeviceProps.Os = proto.String(WhatsAppGetUserOS())
-		store.DeviceProps.PlatformType = WhatsAppGetUserAgent("chrome").Enum()
-		store.DeviceProps.RequireFullSync = proto.Bool(false)
+	// Set Client Properties
+	store.DeviceProps.Os = proto.String(WhatsAppGetUserOS())
+	store.DeviceProps.PlatformType = WhatsAppGetUserAgent("chrome").Enum()
+	store.DeviceProps.RequireFullSync = proto.Bool(false)
 
-		// Set Client Versions
-		version.Major, err = env.GetEnvInt("WHATSAPP_VERSION_MAJOR")
-		if err == nil {
-			store.DeviceProps.Version.Primary = proto.Uint32(uint32(version.Major))
-		}
-		version.Minor, err = env.GetEnvInt("WHATSAPP_VERSION_MINOR")
-		if err == nil {
-			store.DeviceProps.Version.Secondary = proto.Uint32(uint32(version.Minor))
-		}
-		version.Patch, err = env.GetEnvInt("WHATSAPP_VERSION_PATCH")
-		if err == nil {
-			store.DeviceProps.Version.Tertiary = proto.Uint32(uint32(version.Patch))
-		}
+	// Set Client Versions
+	version.Major, err = env.GetEnvInt("WHATSAPP_VERSION_MAJOR")
+	if err == nil {
+		store.DeviceProps.Version.Primary = proto.Uint32(uint32(version.Major))
+	}
+	version.Minor, err = env.GetEnvInt("WHATSAPP_VERSION_MINOR")
+	if err == nil {
+		store.DeviceProps.Version.Secondary = proto.Uint32(uint32(version.Minor))
+	}
+	version.Patch, err = env.GetEnvInt("WHATSAPP_VERSION_PATCH")
+	if err == nil {
+		store.DeviceProps.Version.Tertiary = proto.Uint32(uint32(version.Patch))
+	}
 
-		// Initialize New WhatsApp Client
-		WhatsAppClient = whatsmeow.NewClient(device, nil)
+	// Initialize New WhatsApp Client
+	client := whatsmeow.NewClient(device, log.WhatsAppLogger(log.ComponentClient))
 
-		// Set WhatsApp Client Proxy Address if Proxy URL is Provided
-		if len(WhatsAppClientProxyURL) > 0 {
-			WhatsAppClient.SetProxyAddress(WhatsAppClientProxyURL)
-		}
+	// Set WhatsApp Client Proxy Address if Proxy URL is Provided
+	if len(WhatsAppClientProxyURL) > 0 {
+		client.SetProxyAddress(WhatsAppClientProxyURL)
+	}
 
-		// Set WhatsApp Client Auto Reconnect
-		WhatsAppClient.EnableAutoReconnect = true
+	// Set WhatsApp Client Auto Reconnect
+	client.EnableAutoReconnect = true
 
-		// Set WhatsApp Client Auto Trust Identity
-		WhatsAppClient.AutoTrustIdentity = true
-	}
+	// Set WhatsApp Client Auto Trust Identity
+	client.AutoTrustIdentity = true
+
+	return client
 }
 
 func WhatsAppGetUserAgent(agentType string) waCompanionReg.DeviceProps_PlatformType {
@@ -163,20 +346,20 @@ func WhatsAppGetUserOS() string {
 	}
 }
 
-func WhatsAppLogin(jid string) (string, int, error) {
-	if WhatsAppClient != nil {
+func WhatsAppLogin(client *whatsmeow.Client, jid string) (string, int, error) {
+	if client != nil {
 		// Make Sure WebSocket Connection is Disconnected
-		WhatsAppClient.Disconnect()
+		client.Disconnect()
 
-		if WhatsAppClient.Store.ID == nil {
+		if client.Store.ID == nil {
 			// Connect WebSocket while also Requesting Pairing Code
-			err := WhatsAppClient.Connect()
+			err := client.Connect()
 			if err != nil {
 				return "", 0, err
 			}
 
 			// Request Pairing Code
-			code, err := WhatsAppClient.PairPhone(context.Background(), jid, true, whatsmeow.PairClientChrome, "Chrome ("+WhatsAppGetUserOS()+")")
+			code, err := client.PairPhone(context.Background(), jid, true, whatsmeow.PairClientChrome, "Chrome ("+WhatsAppGetUserOS()+")")
 			if err != nil {
 				return "", 0, err
 			}
@@ -185,7 +368,7 @@ func WhatsAppLogin(jid string) (string, int, error) {
 		} else {
 			// Device ID is Exist
 			// Reconnect WebSocket
-			err := WhatsAppReconnect()
+			err := WhatsAppReconnect(client)
 			if err != nil {
 				return "", 0, err
 			}
@@ -198,15 +381,15 @@ func WhatsAppLogin(jid string) (string, int, error) {
 	return "", 0, errors.New("WhatsApp Client is not Valid")
 }
 
-func WhatsAppReconnect() error {
-	if WhatsAppClient != nil {
+func WhatsAppReconnect(client *whatsmeow.Client) error {
+	if client != nil {
 		// Make Sure WebSocket Connection is Disconnected
-		WhatsAppClient.Disconnect()
+		client.Disconnect()
 
 		// Make Sure Store ID is not Empty
 		// To do Reconnection
-		if WhatsAppClient != nil {
-			err := WhatsAppClient.Connect()
+		if client.Store.ID != nil {
+			err := client.Connect()
 			if err != nil {
 				return err
 			}
@@ -220,30 +403,28 @@ func WhatsAppReconnect() error {
 	return errors.New("WhatsApp Client is not Valid")
 }
 
-func WhatsAppLogout() error {
-	if WhatsAppClient != nil {
+func WhatsAppLogout(client *whatsmeow.Client) error {
+	if client != nil {
 		// Make Sure Store ID is not Empty
-		if WhatsAppClient.Store.ID != nil {
+		if client.Store.ID != nil {
 			var err error
 
 			// Set WhatsApp Client Presence to Unavailable
-			WhatsAppPresence(false)
+			_ = client.SendPresence(context.Background(), types.PresenceUnavailable)
 
 			// Logout WhatsApp Client and Disconnect from WebSocket
-			err = WhatsAppClient.Logout(context.Background())
+			err = client.Logout(context.Background())
 			if err != nil {
 				// Force Disconnect
-				WhatsAppClient.Disconnect()
+				client.Disconnect()
 
 				// Manually Delete Device from Datastore Store
-				err = WhatsAppClient.Store.Delete(context.Background())
+				err = client.Store.Delete(context.Background())
 				if err != nil {
 					return err
 				}
 			}
 
-			// Free WhatsApp Client Map
-			WhatsAppClient = nil
 			return nil
 		}
 
@@ -254,15 +435,15 @@ func WhatsAppLogout() error {
 	return errors.New("WhatsApp Client is not Valid")
 }
 
-func WhatsAppPresence(isAvailable bool) {
+func WhatsAppPresence(account *WhatsAppAccount, isAvailable bool) {
 	if isAvailable {
-		_ = WhatsAppClient.SendPresence(context.Background(), types.PresenceAvailable)
+		_ = account.Client.SendPresence(context.Background(), types.PresenceAvailable)
 	} else {
-		_ = WhatsAppClient.SendPresence(context.Background(), types.PresenceUnavailable)
+		_ = account.Client.SendPresence(context.Background(), types.PresenceUnavailable)
 	}
 }
 
-func WhatsAppComposeStatus(rjid types.JID, isComposing bool, isAudio bool) {
+func WhatsAppComposeStatus(account *WhatsAppAccount, rjid types.JID, isComposing bool, isAudio bool) {
 	// Set Compose Status
 	var typeCompose types.ChatPresence
 	if isComposing {
@@ -280,27 +461,93 @@ func WhatsAppComposeStatus(rjid types.JID, isComposing bool, isAudio bool) {
 	}
 
 	// Send Chat Compose Status
-	_ = WhatsAppClient.SendChatPresence(context.Background(), rjid, typeCompose, typeComposeMedia)
+	_ = account.Client.SendChatPresence(context.Background(), rjid, typeCompose, typeComposeMedia)
 }
 
-func WhatsAppSendGPTResponse(event *events.Message, response string) (string, error) {
-	if WhatsAppClient != nil {
+func WhatsAppReaction(account *WhatsAppAccount, event *events.Message, reaction string) error {
+	if account != nil {
+		// Skip Reaction if Reaction Status is Disabled
+		if !WhatsAppGPTReaction {
+			return nil
+		}
+
+		// Make Sure WhatsApp Client is OK
+		if account.IsReady() {
+			// Compose WhatsApp Reaction Proto
+			// An Empty Reaction Will Remove Previous Reaction
+			msgContent := account.Client.BuildReaction(event.Info.Chat, event.Info.Sender, event.Info.ID, reaction)
+
+			// Send WhatsApp Reaction Proto
+			_, err := account.Client.SendMessage(context.Background(), event.Info.Chat, msgContent)
+			if err != nil {
+				return err
+			}
+
+			return nil
+		} else {
+			return errors.New("WhatsApp Client is not Connected or Logged-in")
+		}
+	}
+
+	// Return Error WhatsApp Client is not Valid
+	return errors.New("WhatsApp Client is not Valid")
+}
+
+func whatsAppIsRetryableSend(err error) bool {
+	var netError net.Error
+
+	switch {
+	case errors.Is(err, whatsmeow.ErrNotConnected),
+		errors.Is(err, whatsmeow.ErrIQTimedOut),
+		errors.Is(err, whatsmeow.ErrMessageTimedOut),
+		errors.Is(err, whatsmeow.ErrIQDisconnected),
+		errors.As(err, &netError):
+		return true
+	default:
+		return false
+	}
+}
+
+// whatsAppSendMessage sends the message with bounded retries on transient
+// errors. The message ID is kept between retries so WhatsApp will not
+// deliver the message twice, and the retry stops when the context is done.
+func whatsAppSendMessage(ctx context.Context, account *WhatsAppAccount, rjid types.JID, msgContent *waE2E.Message, msgExtra whatsmeow.SendRequestExtra) error {
+	for attempt := 0; ; attempt++ {
+		_, err := account.Client.SendMessage(ctx, rjid, msgContent, msgExtra)
+		if err == nil || !whatsAppIsRetryableSend(err) || attempt >= WhatsAppGPTSendRetry {
+			return err
+		}
+
+		delay := time.Second << attempt
+
+		log.WithError(err).WithFields(account.logFields()).WithFields(log.Fields{log.FieldChat: WhatsAppMaskJID(rjid), log.FieldMessageID: msgExtra.ID, "attempt": attempt + 1, "delay": delay.String()}).Println(log.LogLevelWarn, "Retrying WhatsApp Message Send")
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+func WhatsAppSendGPTResponse(ctx context.Context, account *WhatsAppAccount, event *events.Message, response string) (string, error) {
+	if account != nil {
 		var err error
 
 		// Make Sure WhatsApp Client is OK
-		if WhatsAppClient.IsConnected() && WhatsAppClient.IsLoggedIn() {
+		if account.IsReady() {
 			rJID := event.Info.Chat
 
 			// Compose WhatsApp Proto
 			msgExtra := whatsmeow.SendRequestExtra{
-				ID: WhatsAppClient.GenerateMessageID(),
+				ID: account.Client.GenerateMessageID(),
 			}
 			msgContent := &waE2E.Message{
 				Conversation: proto.String(response),
 			}
 
 			// Send WhatsApp Message Proto
-			_, err = WhatsAppClient.SendMessage(context.Background(), rJID, msgContent, msgExtra)
+			err = whatsAppSendMessage(ctx, account, rJID, msgContent, msgExtra)
 			if err != nil {
 				return "", err
 			}
@@ -315,57 +562,494 @@ func WhatsAppSendGPTResponse(event *events.Message, response string) (string, er
 	return "", errors.New("WhatsApp Client is not Valid")
 }
 
-func WhatsAppHandler(event interface{}) {
-	switch evt := event.(type) {
-	case *events.Message:
-		realRJID := evt.Info.Chat.String()
+func WhatsAppSendDocument(ctx context.Context, account *WhatsAppAccount, event *events.Message, fileName string, mimeType string, content []byte) (string, error) {
+	if account != nil {
+		// Make Sure WhatsApp Client is OK
+		if account.IsReady() {
+			rJID := event.Info.Chat
+
+			// Upload Document to WhatsApp Media Server
+			uploaded, err := account.Client.Upload(ctx, content, whatsmeow.MediaDocument)
+			if err != nil {
+				return "", err
+			}
+
+			// Compose WhatsApp Proto
+			msgExtra := whatsmeow.SendRequestExtra{
+				ID: account.Client.GenerateMessageID(),
+			}
+			msgContent := &waE2E.Message{
+				DocumentMessage: &waE2E.DocumentMessage{
+					URL:           proto.String(uploaded.URL),
+					DirectPath:    proto.String(uploaded.DirectPath),
+					MediaKey:      uploaded.MediaKey,
+					FileEncSHA256: uploaded.FileEncSHA256,
+					FileSHA256:    uploaded.FileSHA256,
+					FileLength:    proto.Uint64(uploaded.FileLength),
+					Mimetype:      proto.String(mimeType),
+					FileName:      proto.String(fileName),
+					Title:         proto.String(fileName),
+				},
+			}
 
-		var maskRJID string
-		if strings.ContainsRune(realRJID, '-') {
-			splitRJID := strings.Split(realRJID, "-")
+			// Send WhatsApp Message Proto
+			err = whatsAppSendMessage(ctx, account, rJID, msgContent, msgExtra)
+			if err != nil {
+				return "", err
+			}
 
-			realRJID = splitRJID[0]
-			maskRJID = realRJID[0:len(realRJID)-4] + "xxxx" + "-" + splitRJID[1]
+			return msgExtra.ID, nil
 		} else {
-			splitRJID := strings.Split(realRJID, "@")
+			return "", errors.New("WhatsApp Client is not Connected or Logged-in")
+		}
+	}
+
+	// Return Error WhatsApp Client is not Valid
+	return "", errors.New("WhatsApp Client is not Valid")
+}
+
+// WhatsAppMaskJID hides the last 4 digits of the JID user, a JID which is
+// too short to be masked is returned as is.
+func WhatsAppMaskJID(jid types.JID) string {
+	realJID := jid.String()
+
+	separator := "@"
+	if strings.ContainsRune(realJID, '-') {
+		separator = "-"
+	}
+
+	user, rest, isFound := strings.Cut(realJID, separator)
+	if !isFound || len(user) < 4 {
+		return realJID
+	}
+
+	return user[0:len(user)-4] + "xxxx" + separator + rest
+}
+
+func whatsAppLogFields(event *events.Message) log.Fields {
+	return log.Fields{
+		log.FieldChat:      WhatsAppMaskJID(event.Info.Chat),
+		log.FieldSender:    WhatsAppMaskJID(event.Info.Sender.ToNonAD()),
+		log.FieldMessageID: event.Info.ID,
+	}
+}
+
+func WhatsAppChatType(jid types.JID) string {
+	if jid.Server == types.GroupServer {
+		return metrics.ChatTypeGroup
+	}
+
+	return metrics.ChatTypePrivate
+}
+
+func WhatsAppMessageText(message *waE2E.Message) string {
+	switch {
+	case len(message.GetConversation()) > 0:
+		return message.GetConversation()
+	case len(message.GetExtendedTextMessage().GetText()) > 0:
+		return message.GetExtendedTextMessage().GetText()
+	case len(message.GetImageMessage().GetCaption()) > 0:
+		return message.GetImageMessage().GetCaption()
+	case len(message.GetVideoMessage().GetCaption()) > 0:
+		return message.GetVideoMessage().GetCaption()
+	case len(message.GetDocumentMessage().GetCaption()) > 0:
+		return message.GetDocumentMessage().GetCaption()
+	default:
+		return ""
+	}
+}
+
+func WhatsAppMessageContextInfo(message *waE2E.Message) *waE2E.ContextInfo {
+	switch {
+	case message.GetExtendedTextMessage() != nil:
+		return message.GetExtendedTextMessage().GetContextInfo()
+	case message.GetImageMessage() != nil:
+		return message.GetImageMessage().GetContextInfo()
+	case message.GetVideoMessage() != nil:
+		return message.GetVideoMessage().GetContextInfo()
+	case message.GetDocumentMessage() != nil:
+		return message.GetDocumentMessage().GetContextInfo()
+	default:
+		return nil
+	}
+}
+
+func WhatsAppQuotedMessageText(message *waE2E.Message) string {
+	contextInfo := WhatsAppMessageContextInfo(message)
+	if contextInfo.GetQuotedMessage() == nil {
+		return ""
+	}
+
+	return WhatsAppMessageText(contextInfo.GetQuotedMessage())
+}
 
-			realRJID = splitRJID[0]
-			maskRJID = realRJID[0:len(realRJID)-4] + "xxxx" + "@" + splitRJID[1]
+func WhatsAppChatLanguage(rjid types.JID) string {
+	language, err := pkgDatastore.GetChatLanguage(context.Background(), rjid.String())
+	if err != nil {
+		log.WithError(err).WithFields(log.Fields{log.FieldChat: WhatsAppMaskJID(rjid)}).Println(log.LogLevelWarn, "Failed to Get Chat Language from Datastore")
+	}
+
+	if len(language) == 0 || !i18n.IsSupported(language) {
+		return i18n.DefaultLanguage
+	}
+
+	return language
+}
+
+func WhatsAppHandler(account *WhatsAppAccount, event interface{}) {
+	switch evt := event.(type) {
+	case *events.Message:
+		// Never Respond to Own Messages
+		if evt.Info.IsFromMe {
+			return
 		}
 
-		rMessage := strings.TrimSpace(evt.Message.GetConversation())
+		rMessage := strings.TrimSpace(WhatsAppMessageText(evt.Message))
+
+		tagRegex := account.tagRegex()
 
-		if bool(WhatsAppGPTTagRegex.MatchString(rMessage)) {
-			rMessageSplit := WhatsAppGPTTagRegex.Split(rMessage, 2)
+		if bool(tagRegex.MatchString(rMessage)) {
+			rMessageSplit := tagRegex.Split(rMessage, 2)
 
 			if len(rMessageSplit) == 2 {
 				question := strings.TrimSpace(rMessageSplit[1])
 
 				if len(question) > 0 {
-					log.Println(log.LogLevelInfo, "-== Incomming Question ==-")
-					log.Println(log.LogLevelInfo, "From     : "+maskRJID)
-					log.Println(log.LogLevelInfo, "Question : "+question)
-
-					// Set Chat Presence
-					WhatsAppPresence(true)
-					WhatsAppComposeStatus(evt.Info.Chat, true, false)
-					defer func() {
-						WhatsAppComposeStatus(evt.Info.Chat, false, false)
-						WhatsAppPresence(false)
-					}()
-
-					response, err := gpt.GPTResponse(question)
-					if err != nil || len(response) == 0 {
-						log.Println(log.LogLevelError, err.Error())
-						response = "Sorry, the AI can not response for this time. Please try again after a few moment 🥺"
-					}
+					chatType := WhatsAppChatType(evt.Info.Chat)
+					metrics.QuestionReceived.WithLabelValues(chatType).Inc()
+
+					ctx, span := tracing.StartSpan(context.Background(), "whatsapp.message.receive",
+						tracing.AttributeMessageID.String(evt.Info.ID), tracing.AttributeChatType.String(chatType))
+					defer span.End()
 
-					_, err = WhatsAppSendGPTResponse(evt, response)
-					if err != nil {
-						log.Println(log.LogLevelError, "Failed to Send OpenAI GPT Response")
+					isAccepted, isLowPriority := whatsAppGuard(account, evt, question, span)
+					if !isAccepted {
+						return
 					}
+
+					WhatsAppQueuePush(ctx, account, evt, question, isLowPriority)
+				}
+			}
+		}
+	}
+}
+
+// whatsAppGuard applies the handler guards to the question and reports
+// whether the question is answered and whether it has low priority.
+func whatsAppGuard(account *WhatsAppAccount, evt *events.Message, question string, span oteltrace.Span) (bool, bool) {
+	chatType := WhatsAppChatType(evt.Info.Chat)
+
+	// Ignore Question from Chat or Sender not Allowed for The Account
+	if !account.IsAllowed(evt) {
+		log.WithFields(whatsAppLogFields(evt)).WithFields(account.logFields()).Println(log.LogLevelInfo, "Ignoring Question Not Allowed for WhatsApp Account")
+		WhatsAppAudit(evt, pkgDatastore.AuditReasonACL, "ignore", "account "+WhatsAppMaskJID(account.JID))
+		metrics.QuestionBlocked.WithLabelValues(chatType, pkgDatastore.AuditReasonACL).Inc()
+		span.SetAttributes(tracing.AttributeIgnored.String(pkgDatastore.AuditReasonACL))
+		return false, false
+	}
+
+	// Ignore Stale Question Delivered After Reconnecting
+	if WhatsAppGPTStaleAction == StaleActionIgnore && WhatsAppIsStale(evt) {
+		log.WithFields(whatsAppLogFields(evt)).WithFields(log.Fields{"sent_at": evt.Info.Timestamp.Format(time.RFC3339)}).Println(log.LogLevelInfo, "Ignoring Stale Question")
+		metrics.QuestionBlocked.WithLabelValues(chatType, "stale").Inc()
+		span.SetAttributes(tracing.AttributeIgnored.String("stale"))
+		return false, false
+	}
+
+	isLowPriority := false
+
+	// Ignore or Deprioritize Frequently Forwarded Messages
+	contextInfo := WhatsAppMessageContextInfo(evt.Message)
+	if contextInfo.GetIsForwarded() && int(contextInfo.GetForwardingScore()) >= WhatsAppGPTForwardedScore {
+		switch WhatsAppGPTForwardedAction {
+		case ForwardedActionIgnore:
+			log.WithFields(whatsAppLogFields(evt)).Println(log.LogLevelInfo, "Ignoring Forwarded Question")
+			WhatsAppAudit(evt, pkgDatastore.AuditReasonRateLimit, "ignore", "forwarded (score "+strconv.Itoa(int(contextInfo.GetForwardingScore()))+")")
+			metrics.QuestionBlocked.WithLabelValues(chatType, "forwarded").Inc()
+			span.SetAttributes(tracing.AttributeIgnored.String("forwarded"))
+			return false, false
+		case ForwardedActionDeprioritize:
+			isLowPriority = true
+		}
+	}
+
+	if !whatsAppGuardClaim(evt, span) {
+		return false, false
+	}
+
+	// Answer Repeated Identical Question Only Once
+	if WhatsAppIsFlood(evt.Info.Chat, question) {
+		whatsAppCompleteProcessed(evt)
+		log.WithFields(whatsAppLogFields(evt)).Println(log.LogLevelInfo, "Ignoring Repeated Question")
+		WhatsAppAudit(evt, pkgDatastore.AuditReasonRateLimit, "ignore", "flood")
+		metrics.QuestionBlocked.WithLabelValues(chatType, "flood").Inc()
+		span.SetAttributes(tracing.AttributeIgnored.String("flood"))
+		return false, false
+	}
+
+	return true, isLowPriority
+}
+
+// whatsAppGuardClaim ignores questions from banned senders and claims the
+// message, so each message is answered exactly once.
+func whatsAppGuardClaim(evt *events.Message, span oteltrace.Span) bool {
+	// Ignore Temporarily Banned Sender
+	if WhatsAppIsBanned(evt) {
+		log.WithFields(whatsAppLogFields(evt)).Println(log.LogLevelInfo, "Ignoring Question from Banned Sender")
+		WhatsAppAudit(evt, pkgDatastore.AuditReasonBan, "ignore", "banned sender")
+		metrics.QuestionBlocked.WithLabelValues(WhatsAppChatType(evt.Info.Chat), pkgDatastore.AuditReasonBan).Inc()
+		span.SetAttributes(tracing.AttributeIgnored.String(pkgDatastore.AuditReasonBan))
+		return false
+	}
+
+	// Make Sure Each Message is Answered Exactly Once
+	if WhatsAppIsProcessed(evt) {
+		log.WithFields(whatsAppLogFields(evt)).Println(log.LogLevelInfo, "Ignoring Already Processed Message")
+		span.SetAttributes(tracing.AttributeIgnored.String("processed"))
+		return false
+	}
+
+	return true
+}
+
+// WhatsAppProcessQuestion answers the question and reports false when the
+// processing is cancelled before the question is answered.
+func WhatsAppProcessQuestion(ctx context.Context, account *WhatsAppAccount, evt *events.Message, question string) bool {
+	ctx, span := tracing.StartSpan(ctx, "whatsapp.question.process", tracing.AttributeMessageID.String(evt.Info.ID))
+	defer span.End()
+
+	// Keep The Question Context While Following Processing Cancellation
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stopCancel := context.AfterFunc(processContext, cancel)
+	defer stopCancel()
+
+	// Answer Using The Account Persona and PII Configuration
+	ctx = gpt.GPTWithPersona(ctx, account.Persona())
+	ctx = gpt.GPTWithPII(ctx, account.PII())
+
+	logEntry := log.WithFields(whatsAppLogFields(evt)).WithFields(account.logFields())
+	chatType := WhatsAppChatType(evt.Info.Chat)
+
+	language := WhatsAppChatLanguage(evt.Info.Chat)
+
+	// Handle Bot Command if Question is a Command
+	if strings.HasPrefix(question, "/") && WhatsAppCommand(ctx, account, evt, question, language) {
+		whatsAppCompleteProcessed(evt)
+		return true
+	}
+
+	// Collect Token Usage of All Completions for The Transcript
+	ctx, usage := gpt.GPTWithUsage(ctx)
+
+	// Instruct Model to Answer in The Question Language
+	var instructions []string
+	if WhatsAppGPTLanguageDetect {
+		if questionLanguage := i18n.Detect(question); len(questionLanguage) > 0 {
+			instructions = append(instructions, "Always answer in "+i18n.LanguageName(questionLanguage)+", "+
+				"the language used by the user, regardless of the language used in previous instructions.")
+		}
+	}
+
+	// Keep Personal Data Out of The Logs When PII Redaction is Enabled
+	loggedQuestion := question
+	if PIIConfig := account.PII(); PIIConfig.Enabled {
+		loggedQuestion, _ = pii.Redact(question, PIIConfig.Detectors)
+	}
+
+	logEntry.WithFields(log.Fields{"question": loggedQuestion}).Println(log.LogLevelInfo, "Incoming Question")
+
+	// Set Reaction as Received Status
+	err := WhatsAppReaction(account, evt, WhatsAppGPTReactionReceived)
+	if err != nil {
+		logEntry.WithError(err).Println(log.LogLevelWarn, "Failed to Send Received Reaction")
+	}
+
+	// Set Chat Presence
+	WhatsAppPresence(account, true)
+	WhatsAppComposeStatus(account, evt.Info.Chat, true, false)
+	defer func() {
+		WhatsAppComposeStatus(account, evt.Info.Chat, false, false)
+		WhatsAppPresence(account, false)
+	}()
+
+	isFailed, isBlocked, isRefused := false, false, false
+
+	var response, reasoning, warning, model string
+
+	if match, isMatched := filter.Check(evt.Info.Chat.String(), question); isMatched {
+		logEntry.WithFields(log.Fields{"term": match.Text, "mode": match.Mode, "source": match.Source}).Println(log.LogLevelWarn, "Question is Blocked by Blocked Word")
+		WhatsAppAudit(evt, pkgDatastore.AuditReasonBlockedWord, "refuse", match.Text+" ("+match.Source+")")
+		WhatsAppStrike(account, evt, "Blocked Word")
+		metrics.QuestionBlocked.WithLabelValues(chatType, pkgDatastore.AuditReasonBlockedWord).Inc()
+		response, isBlocked = i18n.Message(language, i18n.MessageBlockedWord), true
+	} else if moderation := WhatsAppModerate(ctx, evt, question, "Question"); moderation.Flagged && moderation.Action == gpt.ModerationActionRefuse {
+		WhatsAppStrike(account, evt, "Moderation Flag")
+		metrics.QuestionBlocked.WithLabelValues(chatType, pkgDatastore.AuditReasonModeration).Inc()
+		response, isBlocked = i18n.Message(language, i18n.MessageModerationRefused), true
+	} else {
+		if moderation.Flagged && moderation.Action == gpt.ModerationActionWarn {
+			warning = i18n.Message(language, i18n.MessageModerationWarning, strings.Join(moderation.Categories, ", "))
+		}
+
+		startTime := time.Now()
+		model = gpt.GPTModel().Name
+
+		response, reasoning, err = gpt.GPTResponse(ctx, question, instructions...)
+		logEntry = logEntry.WithFields(log.Fields{log.FieldModel: model, log.FieldLatency: time.Since(startTime).Milliseconds()})
+		if errors.Is(err, context.Canceled) {
+			// Remove Received Reaction and Release The Claim When Processing is Cancelled,
+			// The Question is Kept as Pending to be Answered After Restarting
+			logEntry.Println(log.LogLevelWarn, "OpenAI GPT Request is Cancelled")
+			whatsAppReleaseProcessed(evt)
+			whatsAppForgetFlood(evt.Info.Chat, question)
+			_ = WhatsAppReaction(account, evt, "")
+			return false
+		}
+
+		if err != nil || len(response) == 0 {
+			if err != nil {
+				logEntry.WithError(err).Println(log.LogLevelError, "Failed to Get OpenAI GPT Response")
+			}
+
+			response = i18n.Message(language, i18n.MessageFailedResponse)
+			isFailed = true
+		} else {
+			response, reasoning, isRefused = WhatsAppFilterAnswer(ctx, evt, language, question, instructions, response, reasoning)
+
+			if moderation := WhatsAppModerate(ctx, evt, response, "Answer"); moderation.Flagged {
+				switch moderation.Action {
+				case gpt.ModerationActionRefuse:
+					response, reasoning, isRefused = i18n.Message(language, i18n.MessageModerationRefused), "", true
+				case gpt.ModerationActionWarn:
+					warning = i18n.Message(language, i18n.MessageModerationWarning, strings.Join(moderation.Categories, ", "))
+				}
+			}
+
+			// Reasoning is Sent to The User as Well, So Drop It When Flagged
+			if len(reasoning) > 0 {
+				if moderation := WhatsAppModerate(ctx, evt, reasoning, "Reasoning"); moderation.Flagged && moderation.Action != gpt.ModerationActionLog {
+					reasoning = ""
 				}
 			}
 		}
+
+		if len(warning) > 0 {
+			response = warning + "\n\n" + response
+		}
 	}
+
+	answer := response
+
+	// Format Answer Before Sending
+	_, formatSpan := tracing.StartSpan(ctx, "whatsapp.answer.format")
+
+	// Apologize for Answering Stale Question
+	if WhatsAppGPTStaleAction == StaleActionApologize && WhatsAppIsStale(evt) {
+		response = i18n.Message(language, i18n.MessageLateReply) + "\n\n" + response
+	}
+
+	if !isFailed && len(reasoning) > 0 {
+		// Render Reasoning as Quote Block to Keep It Visually Apart
+		reasoning = "> " + strings.ReplaceAll(reasoning, "\n", "\n> ")
+
+		reasoningPrefix := gpt.GPTModel().ReasoningPrefix
+		if len(reasoningPrefix) == 0 {
+			reasoningPrefix = i18n.Message(language, i18n.MessageReasoningPrefix)
+		}
+
+		reasoning = reasoningPrefix + "\n\n" + reasoning
+	}
+
+	// Move Large Code Blocks into Document Attachments
+	var attachments []WhatsAppCodeAttachment
+	if !isFailed && WhatsAppGPTCodeAttachment {
+		response, attachments = WhatsAppExtractCodeAttachment(response, WhatsAppGPTCodeAttachmentSize, language)
+	}
+
+	formatSpan.End()
+
+	// Send Reasoning as Separate Message if Available
+	if !isFailed && len(reasoning) > 0 {
+		sendCtx, sendSpan := tracing.StartSpan(ctx, "whatsapp.answer.send", tracing.AttributeSendType.String(metrics.SendTypeReasoning))
+		_, err = WhatsAppSendGPTResponse(sendCtx, account, evt, reasoning)
+		tracing.EndSpan(sendSpan, err)
+
+		if err != nil {
+			logEntry.WithError(err).Println(log.LogLevelWarn, "Failed to Send OpenAI GPT Reasoning")
+			metrics.SendFailure.WithLabelValues(metrics.SendTypeReasoning).Inc()
+		}
+	}
+
+	sendCtx, sendSpan := tracing.StartSpan(ctx, "whatsapp.answer.send", tracing.AttributeSendType.String(metrics.SendTypeMessage))
+	_, err = WhatsAppSendGPTResponse(sendCtx, account, evt, response)
+	tracing.EndSpan(sendSpan, err)
+
+	if err != nil && ctx.Err() != nil {
+		// Keep The Question as Pending When Sending is Cancelled
+		logEntry.WithError(err).Println(log.LogLevelWarn, "Sending OpenAI GPT Response is Cancelled")
+		whatsAppReleaseProcessed(evt)
+		whatsAppForgetFlood(evt.Info.Chat, question)
+		_ = WhatsAppReaction(account, evt, "")
+		return false
+	}
+
+	if err != nil {
+		logEntry.WithError(err).Println(log.LogLevelError, "Failed to Send OpenAI GPT Response")
+		metrics.SendFailure.WithLabelValues(metrics.SendTypeMessage).Inc()
+		isFailed = true
+	}
+
+	for _, attachment := range attachments {
+		sendCtx, sendSpan := tracing.StartSpan(ctx, "whatsapp.answer.send", tracing.AttributeSendType.String(metrics.SendTypeDocument))
+		_, err = WhatsAppSendDocument(sendCtx, account, evt, attachment.FileName, attachment.MimeType, attachment.Content)
+		tracing.EndSpan(sendSpan, err)
+
+		if err != nil {
+			logEntry.WithError(err).WithFields(log.Fields{"file_name": attachment.FileName}).Println(log.LogLevelError, "Failed to Send OpenAI GPT Code Attachment")
+			metrics.SendFailure.WithLabelValues(metrics.SendTypeDocument).Inc()
+			isFailed = true
+		}
+	}
+
+	if !isFailed && !isBlocked {
+		outcome := metrics.OutcomeAnswered
+		if isRefused {
+			outcome = metrics.OutcomeRefused
+		}
+
+		metrics.QuestionAnswered.WithLabelValues(chatType, outcome).Inc()
+	}
+
+	// Answer Failed Question Again When It is Delivered or Asked Again
+	if isFailed {
+		whatsAppReleaseProcessed(evt)
+		whatsAppForgetFlood(evt.Info.Chat, question)
+	} else {
+		whatsAppCompleteProcessed(evt)
+	}
+
+	switch {
+	case isBlocked:
+		whatsAppSaveConversation(account, evt, question, answer, pkgDatastore.ConversationStatusBlocked, model, usage)
+	case isFailed:
+		whatsAppSaveConversation(account, evt, question, answer, pkgDatastore.ConversationStatusFailed, model, usage)
+	default:
+		whatsAppSaveConversation(account, evt, question, answer, pkgDatastore.ConversationStatusAnswered, model, usage)
+	}
+
+	// Replace Received Reaction with Final Status
+	if isFailed {
+		err = WhatsAppReaction(account, evt, WhatsAppGPTReactionFailure)
+	} else {
+		err = WhatsAppReaction(account, evt, WhatsAppGPTReactionSuccess)
+	}
+
+	if err != nil {
+		logEntry.WithError(err).Println(log.LogLevelWarn, "Failed to Send Status Reaction")
+		metrics.SendFailure.WithLabelValues(metrics.SendTypeReaction).Inc()
+	}
+
+	return true
 }